@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 
@@ -190,6 +191,26 @@ func (a AccountConnReq) MarshalJSON() ([]byte, error) {
 	}
 }
 
+func (a *AccountConnReq) UnmarshalJSON(data []byte) error {
+	var conn AccountConn
+	if err := json.Unmarshal(data, &conn); err != nil {
+		return err
+	}
+
+	switch conn.Type {
+	case AccountConnOpenAI, AccountConnTwilio:
+	default:
+		return fmt.Errorf("unsupported account connection type: %s", conn.Type)
+	}
+
+	*a = AccountConnReq{
+		Type:          conn.Type,
+		TwilioAccount: conn.TwilioAccount,
+		OpenAIAccount: conn.OpenAIAccount,
+	}
+	return nil
+}
+
 type CreateAccountConnReq struct {
 	AccountConnReq
 }
@@ -212,11 +233,9 @@ func (c *Client) ListAccountConns(ctx context.Context, paging *PageParams) (*Acc
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
+	var options []request.HTTPOption
 	if paging != nil {
-		request.WithPageParams(paging.Encode())
+		options = append(options, request.WithPageParams(paging.Encode()))
 	}
 
 	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
@@ -224,7 +243,7 @@ func (c *Client) ListAccountConns(ctx context.Context, paging *PageParams) (*Acc
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -237,17 +256,24 @@ func (c *Client) ListAccountConns(ctx context.Context, paging *PageParams) (*Acc
 	return accountConns, nil
 }
 
+// AllAccountConns returns Pager which iterates over all the account connections.
+func (c *Client) AllAccountConns(ctx context.Context, opts *ListOptions) *Pager[AccountConn] {
+	return newPager(ctx, opts, func(ctx context.Context, paging *PageParams) ([]AccountConn, *Paging, error) {
+		page, err := c.ListAccountConns(ctx, paging)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Items, page.Paging, nil
+	})
+}
+
 func (c *Client) GetAccountConn(ctx context.Context, id string) (*AccountConn, error) {
 	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/account_connections")
 	if err != nil {
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
+	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -255,7 +281,7 @@ func (c *Client) GetAccountConn(ctx context.Context, id string) (*AccountConn, e
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -281,16 +307,12 @@ func (c *Client) CreateAccountConn(ctx context.Context, createReq *CreateAccount
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -316,11 +338,7 @@ func (c *Client) UpdateAccountConn(ctx context.Context, id string, updateReq *Up
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -328,7 +346,7 @@ func (c *Client) UpdateAccountConn(ctx context.Context, id string, updateReq *Up
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -340,3 +358,29 @@ func (c *Client) UpdateAccountConn(ctx context.Context, id string, updateReq *Up
 	}
 	return accountConn, nil
 }
+
+// DeleteAccountConn deletes the account connection with the given id.
+// It returns error matching ErrNotFound if the account connection does not exist.
+func (c *Client) DeleteAccountConn(ctx context.Context, id string) error {
+	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/account_connections/delete")
+	if err != nil {
+		return err
+	}
+
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	q.Add("id", id)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 
@@ -238,17 +239,27 @@ func (a *Action) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (a *ActionReq) UnmarshalJSON(data []byte) error {
+	var action Action
+	if err := json.Unmarshal(data, &action); err != nil {
+		return err
+	}
+
+	a.Type = action.Type
+	a.Trigger = action.Trigger
+	a.Config = action.Config
+	return nil
+}
+
 func (c *Client) ListActions(ctx context.Context, paging *PageParams) (*Actions, error) {
 	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/actions/list")
 	if err != nil {
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
+	var options []request.HTTPOption
 	if paging != nil {
-		request.WithPageParams(paging.Encode())
+		options = append(options, request.WithPageParams(paging.Encode()))
 	}
 
 	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
@@ -256,7 +267,7 @@ func (c *Client) ListActions(ctx context.Context, paging *PageParams) (*Actions,
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -269,17 +280,24 @@ func (c *Client) ListActions(ctx context.Context, paging *PageParams) (*Actions,
 	return actions, nil
 }
 
+// AllActions returns Pager which iterates over all the actions.
+func (c *Client) AllActions(ctx context.Context, opts *ListOptions) *Pager[Action] {
+	return newPager(ctx, opts, func(ctx context.Context, paging *PageParams) ([]Action, *Paging, error) {
+		page, err := c.ListActions(ctx, paging)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Items, page.Paging, nil
+	})
+}
+
 func (c *Client) GetAction(ctx context.Context, id string) (*Action, error) {
 	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/actions")
 	if err != nil {
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
+	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -287,7 +305,7 @@ func (c *Client) GetAction(ctx context.Context, id string) (*Action, error) {
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -313,16 +331,12 @@ func (c *Client) CreateAction(ctx context.Context, createReq *CreateActionReq) (
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -348,11 +362,7 @@ func (c *Client) UpdateAction(ctx context.Context, id string, updateReq *UpdateA
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -360,7 +370,7 @@ func (c *Client) UpdateAction(ctx context.Context, id string, updateReq *UpdateA
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -372,3 +382,29 @@ func (c *Client) UpdateAction(ctx context.Context, id string, updateReq *UpdateA
 	}
 	return action, nil
 }
+
+// DeleteAction deletes the action with the given id.
+// It returns error matching ErrNotFound if the action does not exist.
+func (c *Client) DeleteAction(ctx context.Context, id string) error {
+	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/actions/delete")
+	if err != nil {
+		return err
+	}
+
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	q.Add("id", id)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/url"
 
@@ -147,11 +148,9 @@ func (c *Client) ListAgents(ctx context.Context, paging *PageParams) (*Agents, e
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
+	var options []request.HTTPOption
 	if paging != nil {
-		request.WithPageParams(paging.Encode())
+		options = append(options, request.WithPageParams(paging.Encode()))
 	}
 
 	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
@@ -159,7 +158,7 @@ func (c *Client) ListAgents(ctx context.Context, paging *PageParams) (*Agents, e
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -172,17 +171,24 @@ func (c *Client) ListAgents(ctx context.Context, paging *PageParams) (*Agents, e
 	return agents, nil
 }
 
+// AllAgents returns Pager which iterates over all the agents.
+func (c *Client) AllAgents(ctx context.Context, opts *ListOptions) *Pager[Agent] {
+	return newPager(ctx, opts, func(ctx context.Context, paging *PageParams) ([]Agent, *Paging, error) {
+		page, err := c.ListAgents(ctx, paging)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Items, page.Paging, nil
+	})
+}
+
 func (c *Client) GetAgent(ctx context.Context, id string) (*Agent, error) {
 	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/agents")
 	if err != nil {
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
+	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -190,7 +196,7 @@ func (c *Client) GetAgent(ctx context.Context, id string) (*Agent, error) {
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -216,16 +222,12 @@ func (c *Client) CreateAgent(ctx context.Context, createReq *CreateAgentReq) (*A
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -251,11 +253,7 @@ func (c *Client) UpdateAgent(ctx context.Context, id string, updateReq *UpdateAg
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -263,7 +261,7 @@ func (c *Client) UpdateAgent(ctx context.Context, id string, updateReq *UpdateAg
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -275,3 +273,29 @@ func (c *Client) UpdateAgent(ctx context.Context, id string, updateReq *UpdateAg
 	}
 	return agent, nil
 }
+
+// DeleteAgent deletes the agent with the given id.
+// It returns error matching ErrNotFound if the agent does not exist.
+func (c *Client) DeleteAgent(ctx context.Context, id string) error {
+	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/agents/delete")
+	if err != nil {
+		return err
+	}
+
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	q.Add("id", id)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
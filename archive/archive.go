@@ -0,0 +1,343 @@
+// Package archive archives the call recordings into blob sinks.
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+const (
+	// DefaultMaxConcurrent is the default maximum number of concurrent downloads.
+	DefaultMaxConcurrent = 4
+)
+
+// Filter selects the calls whose recordings are archived.
+type Filter struct {
+	// Since excludes the calls started before it.
+	Since time.Time
+	// Until excludes the calls started at or after it.
+	Until time.Time
+	// Agents limits the archival to the calls handled by the agents.
+	Agents []string
+}
+
+// Match returns true if the call matches the filter.
+// The calls with no known start time never match
+// the filters with the date range set.
+func (f Filter) Match(call *vocode.Call) bool {
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		start, err := time.Parse(time.RFC3339Nano, call.StartTime)
+		if err != nil {
+			return false
+		}
+		if !f.Since.IsZero() && start.Before(f.Since) {
+			return false
+		}
+		if !f.Until.IsZero() && !start.Before(f.Until) {
+			return false
+		}
+	}
+	if len(f.Agents) > 0 {
+		if call.Agent == nil {
+			return false
+		}
+		for _, id := range f.Agents {
+			if call.Agent.ID == id {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// KeyFunc returns the sink key of the call recording.
+type KeyFunc func(call *vocode.Call, rec *vocode.Recording) string
+
+// DefaultKey returns the call ID with the recording format extension.
+func DefaultKey(call *vocode.Call, rec *vocode.Recording) string {
+	return call.ID + "." + string(rec.Format)
+}
+
+// Options configure the Archiver.
+type Options struct {
+	// MaxConcurrent is the maximum number of concurrent downloads.
+	MaxConcurrent int
+	// Manifest records the archived recordings.
+	Manifest Manifest
+	// Filter selects the archived calls.
+	Filter Filter
+	// List configures the listing of the calls.
+	List *vocode.ListOptions
+	// Key returns the sink key of the recording.
+	Key KeyFunc
+	// TempDir is the directory the recordings are downloaded to
+	// before they're stored in the sink. Partial downloads are
+	// kept in it so they're resumed when the archival is rerun.
+	// A new temporary directory is used if it's not set.
+	TempDir string
+	// OnEntry is called with the manifest entry of every archived call.
+	OnEntry func(Entry)
+	// Clock is used for timing the archival.
+	Clock vocode.Clock
+}
+
+// Option is functional archive option.
+type Option func(*Options)
+
+// WithMaxConcurrent sets the maximum number of concurrent downloads.
+func WithMaxConcurrent(n int) Option {
+	return func(o *Options) {
+		o.MaxConcurrent = n
+	}
+}
+
+// WithManifest sets the archive manifest.
+func WithManifest(m Manifest) Option {
+	return func(o *Options) {
+		o.Manifest = m
+	}
+}
+
+// WithDateRange archives the calls started in the [since, until) range.
+// Zero since or until leaves the range open.
+func WithDateRange(since, until time.Time) Option {
+	return func(o *Options) {
+		o.Filter.Since = since
+		o.Filter.Until = until
+	}
+}
+
+// WithAgents archives the calls handled by the agents.
+func WithAgents(ids ...string) Option {
+	return func(o *Options) {
+		o.Filter.Agents = ids
+	}
+}
+
+// WithListOptions sets the call list options.
+func WithListOptions(opts *vocode.ListOptions) Option {
+	return func(o *Options) {
+		o.List = opts
+	}
+}
+
+// WithKey sets the sink key function.
+func WithKey(fn KeyFunc) Option {
+	return func(o *Options) {
+		o.Key = fn
+	}
+}
+
+// WithTempDir sets the recordings download directory.
+func WithTempDir(dir string) Option {
+	return func(o *Options) {
+		o.TempDir = dir
+	}
+}
+
+// WithOnEntry sets the manifest entry callback.
+func WithOnEntry(fn func(Entry)) Option {
+	return func(o *Options) {
+		o.OnEntry = fn
+	}
+}
+
+// WithClock sets the archive clock.
+func WithClock(c vocode.Clock) Option {
+	return func(o *Options) {
+		o.Clock = c
+	}
+}
+
+// Report is the archival report.
+type Report struct {
+	// Archived is the number of recordings archived by the run.
+	Archived int
+	// Skipped is the number of recordings archived by the previous runs.
+	Skipped int
+	// Failed contains the entries of the recordings which failed to archive.
+	Failed []Entry
+}
+
+// Archiver archives the call recordings.
+type Archiver struct {
+	client *vocode.Client
+	sink   Sink
+	opts   Options
+}
+
+// NewArchiver creates a new Archiver storing the recordings in sink and returns it.
+func NewArchiver(c *vocode.Client, sink Sink, opts ...Option) *Archiver {
+	options := Options{
+		MaxConcurrent: DefaultMaxConcurrent,
+		Key:           DefaultKey,
+		Clock:         vocode.DefaultClock,
+	}
+	for _, apply := range opts {
+		apply(&options)
+	}
+	if options.MaxConcurrent < 1 {
+		options.MaxConcurrent = 1
+	}
+	if options.Manifest == nil {
+		options.Manifest = NewMemManifest()
+	}
+
+	return &Archiver{
+		client: c,
+		sink:   sink,
+		opts:   options,
+	}
+}
+
+// Run archives the recordings of all the listed calls which have their
+// recording available and match the filter. The recordings archived
+// by the previous runs recorded in the manifest are skipped.
+// Run returns the report once all the calls have been listed and their
+// recordings archived, or when ctx is done, the calls can't be listed,
+// the manifest can't be saved or the API rejects the client credentials.
+func (a *Archiver) Run(ctx context.Context) (*Report, error) {
+	prev, err := a.opts.Manifest.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load manifest: %w", err)
+	}
+
+	dir := a.opts.TempDir
+	if dir == "" {
+		if dir, err = os.MkdirTemp("", "vocode-archive-*"); err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		report = &Report{}
+		slots  = make(chan struct{}, a.opts.MaxConcurrent)
+	)
+
+	p := a.client.AllCalls(ctx, a.opts.List)
+loop:
+	for p.Next() {
+		call := p.Item()
+		if !call.RecordAvailable || !a.opts.Filter.Match(&call) {
+			continue
+		}
+		if e, ok := prev[call.ID]; ok && e.Status == StatusArchived {
+			report.Skipped++
+			continue
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			break loop
+		}
+
+		wg.Add(1)
+		go func(call vocode.Call) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			e, err := a.archive(ctx, dir, &call)
+			if errors.Is(err, vocode.ErrUnauthorized) {
+				// NOTE: no other recording can be downloaded either
+				cancel(err)
+			}
+			if err != nil && ctx.Err() != nil {
+				return
+			}
+			if err := a.opts.Manifest.Save(context.WithoutCancel(ctx), &e); err != nil {
+				cancel(fmt.Errorf("save manifest: %w", err))
+				return
+			}
+
+			mu.Lock()
+			if e.Status == StatusArchived {
+				report.Archived++
+			} else {
+				report.Failed = append(report.Failed, e)
+			}
+			mu.Unlock()
+
+			if a.opts.OnEntry != nil {
+				a.opts.OnEntry(e)
+			}
+		}(call)
+	}
+
+	wg.Wait()
+
+	if cause := context.Cause(ctx); cause != nil {
+		return report, cause
+	}
+	return report, p.Err()
+}
+
+// archive downloads the recording of the call and stores it in the sink.
+func (a *Archiver) archive(ctx context.Context, dir string, call *vocode.Call) (Entry, error) {
+	e := Entry{
+		CallID: call.ID,
+		Status: StatusFailed,
+	}
+
+	fail := func(err error) (Entry, error) {
+		e.Error = err.Error()
+		e.ArchivedAt = a.opts.Clock.Now().UTC()
+		return e, err
+	}
+
+	path, err := recordingPath(dir, call.ID)
+	if err != nil {
+		return fail(err)
+	}
+
+	rec, err := a.client.DownloadRecording(ctx, call.ID, path)
+	if err != nil {
+		return fail(err)
+	}
+	defer os.Remove(rec.Path)
+
+	e.Key = a.opts.Key(call, rec)
+	e.Format = rec.Format
+	e.SHA256 = rec.SHA256
+	e.Size = rec.Size
+
+	f, err := os.Open(rec.Path)
+	if err != nil {
+		return fail(err)
+	}
+	defer f.Close()
+
+	if err := a.sink.Put(ctx, e.Key, f, rec.Size); err != nil {
+		return fail(err)
+	}
+
+	e.Status = StatusArchived
+	e.ArchivedAt = a.opts.Clock.Now().UTC()
+	return e, nil
+}
+
+// recordingPath returns the path of the recording of the call with the given ID
+// downloaded into dir. The ID must not escape dir since it comes from the API.
+func recordingPath(dir, id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("invalid call ID: %q", id)
+	}
+	return filepath.Join(dir, id), nil
+}
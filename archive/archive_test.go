@@ -0,0 +1,230 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/vocodetest"
+)
+
+// failSink fails to store the key once.
+type failSink struct {
+	Sink
+	key    string
+	failed bool
+}
+
+func (s *failSink) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	if key == s.key && !s.failed {
+		s.failed = true
+		return errors.New("sink unavailable")
+	}
+	return s.Sink.Put(ctx, key, r, size)
+}
+
+// setup creates n calls with their recordings set to
+// their call ID and returns the client and the call IDs.
+func setup(t *testing.T, n int) (*vocode.Client, []string) {
+	t.Helper()
+
+	s := vocodetest.NewServer()
+	t.Cleanup(s.Close)
+	c := s.Client()
+	ctx := context.Background()
+
+	prompt, err := c.CreatePrompt(ctx, &vocode.CreatePromptReq{PromptReq: vocode.PromptReq{Content: "test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	voice, err := c.CreateVoice(ctx, &vocode.CreateVoiceReq{VoiceReq: vocode.VoiceReq{Type: vocode.RimeVoiceType, RimeVoice: &vocode.RimeVoice{Speaker: "test"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent, err := c.CreateAgent(ctx, &vocode.CreateAgentReq{AgentReq: vocode.AgentReq{Name: "test", Prompt: prompt.ID, Voice: voice.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	number, err := c.BuyNumber(ctx, &vocode.BuyNumberReq{AreaCode: "415", TelProvider: vocode.TwilioTelProvider})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for i := 0; i <= n; i++ {
+		call, err := c.CreateCall(ctx, &vocode.CreateCallReq{FromNr: number.Number, ToNr: "+15550001", Agent: agent.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// NOTE: the last call has no recording
+		if i == n {
+			break
+		}
+		if err := s.SetRecording(call.ID, []byte(call.ID)); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, call.ID)
+	}
+	return c, ids
+}
+
+func TestArchiver(t *testing.T) {
+	t.Parallel()
+	c, ids := setup(t, 3)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	fs, err := NewFSSink(filepath.Join(dir, "recordings"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := NewFileManifest(filepath.Join(dir, "manifest.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manifest.Close()
+
+	sink := &failSink{Sink: fs, key: ids[1] + ".wav"}
+	a := NewArchiver(c, sink, WithManifest(manifest), WithMaxConcurrent(2))
+
+	report, err := a.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Archived != 2 || report.Skipped != 0 || len(report.Failed) != 1 || report.Failed[0].CallID != ids[1] {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	// the rerun only retries the failed recording
+	report, err = a.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Archived != 1 || report.Skipped != 2 || len(report.Failed) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	entries, err := manifest.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(ids) {
+		t.Fatalf("expected manifest entries: %d, got: %d", len(ids), len(entries))
+	}
+	for _, id := range ids {
+		e := entries[id]
+		sum := sha256.Sum256([]byte(id))
+		if e.Status != StatusArchived || e.Key != id+".wav" || e.SHA256 != hex.EncodeToString(sum[:]) || e.Size != int64(len(id)) {
+			t.Fatalf("unexpected manifest entry: %+v", e)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "recordings", e.Key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, []byte(id)) {
+			t.Fatalf("expected recording: %q, got: %q", id, data)
+		}
+	}
+}
+
+func TestArchiverFilter(t *testing.T) {
+	t.Parallel()
+	c, _ := setup(t, 2)
+	ctx := context.Background()
+
+	fs, err := NewFSSink(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name string
+		opt  Option
+		exp  int
+	}{
+		{"agents", WithAgents("unknown"), 0},
+		{"future", WithDateRange(time.Now().Add(time.Hour), time.Time{}), 0},
+		{"past", WithDateRange(time.Time{}, time.Now().Add(-time.Hour)), 0},
+		{"range", WithDateRange(time.Now().Add(-time.Hour), time.Now().Add(time.Hour)), 2},
+	}
+	for _, tc := range testCases {
+		report, err := NewArchiver(c, fs, tc.opt).Run(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Archived != tc.exp {
+			t.Fatalf("%s: expected archived: %d, got: %d", tc.name, tc.exp, report.Archived)
+		}
+	}
+}
+
+func TestFileManifest(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "manifest.jsonl")
+	// the process crashed while saving the second entry
+	if err := os.WriteFile(path, []byte(`{"call_id":"a","status":"archived"}`+"\n"+`{"call_id":"b","sta`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	manifest, err := NewFileManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manifest.Close()
+	if err := manifest.Save(ctx, &Entry{CallID: "c", Status: StatusFailed, Error: "boom"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the saved entry is not appended to the partial one
+	entries, err := manifest.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries["a"] == nil || entries["c"] == nil || entries["c"].Error != "boom" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestFSSink(t *testing.T) {
+	t.Parallel()
+	s, err := NewFSSink(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"", "../escape", "/abs"} {
+		if err := s.Put(context.Background(), key, bytes.NewReader(nil), 0); err == nil {
+			t.Fatalf("expected invalid key error: %q", key)
+		}
+	}
+	if err := s.Put(context.Background(), "a/b.wav", bytes.NewReader([]byte("x")), 2); err == nil {
+		t.Fatal("expected size mismatch error")
+	}
+}
+
+func TestRecordingPath(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join("tmp", "recordings")
+
+	path, err := recordingPath(dir, "call-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := filepath.Join(dir, "call-1"); path != exp {
+		t.Fatalf("expected path: %s, got: %s", exp, path)
+	}
+
+	for _, id := range []string{"", ".", "..", "../call-1", "calls/call-1", `..\call-1`} {
+		if _, err := recordingPath(dir, id); err == nil {
+			t.Fatalf("expected invalid call ID error: %q", id)
+		}
+	}
+}
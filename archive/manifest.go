@@ -0,0 +1,126 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/internal/jsonl"
+)
+
+// Status is the archival status of the call recording.
+type Status string
+
+const (
+	// StatusArchived recordings have been stored in the sink.
+	StatusArchived Status = "archived"
+	// StatusFailed recordings could not be archived.
+	// They are retried when the archival is rerun.
+	StatusFailed Status = "failed"
+)
+
+// Entry is the manifest entry of the call recording.
+type Entry struct {
+	CallID     string             `json:"call_id"`
+	Key        string             `json:"key,omitempty"`
+	Format     vocode.AudioFormat `json:"format,omitempty"`
+	SHA256     string             `json:"sha256,omitempty"`
+	Size       int64              `json:"size"`
+	Status     Status             `json:"status"`
+	Error      string             `json:"error,omitempty"`
+	ArchivedAt time.Time          `json:"archived_at"`
+}
+
+// Manifest records the archived recordings so
+// reruns skip them and retry the failed ones.
+type Manifest interface {
+	// Load returns the latest entries of all the calls keyed by their ID.
+	Load(ctx context.Context) (map[string]*Entry, error)
+	// Save stores the latest entry of the call.
+	Save(ctx context.Context, e *Entry) error
+}
+
+// MemManifest is a Manifest which keeps the entries in memory.
+type MemManifest struct {
+	mu      sync.Mutex
+	entries map[string]*Entry
+}
+
+// NewMemManifest creates a new MemManifest and returns it.
+func NewMemManifest() *MemManifest {
+	return &MemManifest{
+		entries: make(map[string]*Entry),
+	}
+}
+
+// Load implements Manifest.
+func (m *MemManifest) Load(context.Context) (map[string]*Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := make(map[string]*Entry, len(m.entries))
+	for id, e := range m.entries {
+		entry := *e
+		entries[id] = &entry
+	}
+	return entries, nil
+}
+
+// Save implements Manifest.
+func (m *MemManifest) Save(_ context.Context, e *Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := *e
+	m.entries[e.CallID] = &entry
+	return nil
+}
+
+// FileManifest is a Manifest which appends every entry
+// to a JSONL file. When the file is loaded the last
+// entry stored for the call wins.
+type FileManifest struct {
+	log *jsonl.Log
+}
+
+// NewFileManifest opens the JSONL file at path,
+// creating it if necessary, and returns the manifest.
+func NewFileManifest(path string) (*FileManifest, error) {
+	log, err := jsonl.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileManifest{
+		log: log,
+	}, nil
+}
+
+// Load implements Manifest.
+// It fails if the file contains a corrupt entry.
+func (m *FileManifest) Load(context.Context) (map[string]*Entry, error) {
+	entries := make(map[string]*Entry)
+	err := m.log.Load(func(line []byte) error {
+		e := new(Entry)
+		if err := json.Unmarshal(line, e); err != nil {
+			return err
+		}
+		entries[e.CallID] = e
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Save implements Manifest.
+func (m *FileManifest) Save(_ context.Context, e *Entry) error {
+	return m.log.Append(e)
+}
+
+// Close closes the manifest file.
+func (m *FileManifest) Close() error {
+	return m.log.Close()
+}
@@ -0,0 +1,96 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sink stores the archived recordings.
+// It can be implemented by any blob store e.g. S3-compatible ones.
+type Sink interface {
+	// Put stores size bytes read from r under key. The key is a slash
+	// separated path. Put must not leave a partial object under key
+	// if it fails and it must replace any existing object.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+}
+
+// FSSink is a Sink which stores the recordings in a local directory.
+type FSSink struct {
+	dir string
+}
+
+// NewFSSink creates a new FSSink storing
+// the recordings in dir and returns it.
+// The directory is created if it doesn't exist.
+func NewFSSink(dir string) (*FSSink, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FSSink{dir: dir}, nil
+}
+
+// Put implements Sink. The object is written atomically
+// via a temporary file renamed to its final path.
+func (s *FSSink) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".archive-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, ctxReader{ctx: ctx, r: r})
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if size >= 0 && n != size {
+		tmp.Close()
+		return fmt.Errorf("put %s: wrote %d of %d bytes", key, n, size)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// path returns the path of the key. It returns
+// error if the key would escape the sink directory.
+func (s *FSSink) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid key: %q", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
+
+// ctxReader stops reading once the context is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
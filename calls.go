@@ -107,11 +107,9 @@ func (c *Client) ListCalls(ctx context.Context, paging *PageParams) (*Calls, err
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
+	var options []request.HTTPOption
 	if paging != nil {
-		request.WithPageParams(paging.Encode())
+		options = append(options, request.WithPageParams(paging.Encode()))
 	}
 
 	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
@@ -119,7 +117,7 @@ func (c *Client) ListCalls(ctx context.Context, paging *PageParams) (*Calls, err
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -132,17 +130,24 @@ func (c *Client) ListCalls(ctx context.Context, paging *PageParams) (*Calls, err
 	return calls, nil
 }
 
+// AllCalls returns Pager which iterates over all the calls.
+func (c *Client) AllCalls(ctx context.Context, opts *ListOptions) *Pager[Call] {
+	return newPager(ctx, opts, func(ctx context.Context, paging *PageParams) ([]Call, *Paging, error) {
+		page, err := c.ListCalls(ctx, paging)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Items, page.Paging, nil
+	})
+}
+
 func (c *Client) GetCall(ctx context.Context, id string) (*Call, error) {
 	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/calls")
 	if err != nil {
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
+	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -150,7 +155,7 @@ func (c *Client) GetCall(ctx context.Context, id string) (*Call, error) {
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -164,6 +169,12 @@ func (c *Client) GetCall(ctx context.Context, id string) (*Call, error) {
 }
 
 func (c *Client) CreateCall(ctx context.Context, createReq *CreateCallReq) (*Call, error) {
+	if c.opts.UsageGuard != nil {
+		if _, err := c.opts.UsageGuard.Check(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+
 	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/calls/create")
 	if err != nil {
 		return nil, err
@@ -176,16 +187,12 @@ func (c *Client) CreateCall(ctx context.Context, createReq *CreateCallReq) (*Cal
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -212,11 +219,7 @@ func (c *Client) EndCall(ctx context.Context, id string) (*Call, error) {
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -224,7 +227,7 @@ func (c *Client) EndCall(ctx context.Context, id string) (*Call, error) {
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -243,11 +246,7 @@ func (c *Client) GetCallRecording(ctx context.Context, id string, w io.Writer) e
 		return err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
+	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return err
 	}
@@ -255,7 +254,7 @@ func (c *Client) GetCallRecording(ctx context.Context, id string, w io.Writer) e
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
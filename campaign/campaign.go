@@ -0,0 +1,427 @@
+// Package campaign runs outbound call campaigns.
+package campaign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/client"
+)
+
+const (
+	// DefaultMaxConcurrent is the default maximum number of concurrent calls.
+	DefaultMaxConcurrent = 5
+)
+
+// Status is the status of the campaign target.
+type Status string
+
+const (
+	// StatusPlacing targets are being called and it is not known yet
+	// whether the call has been placed. Their result is saved before
+	// the call is placed so if the campaign is interrupted they are not
+	// called again on resume; the call might have been placed already.
+	StatusPlacing Status = "placing"
+	// StatusCalling targets have been called and the call is being tracked.
+	StatusCalling Status = "calling"
+	// StatusCompleted targets have been called and the call has ended.
+	StatusCompleted Status = "completed"
+	// StatusFailed targets could not be called or the call has failed.
+	StatusFailed Status = "failed"
+	// StatusSkipped targets have not been called because the campaign
+	// budget has been exhausted or the campaign has been interrupted,
+	// or the call could not be placed because of a retryable error.
+	StatusSkipped Status = "skipped"
+)
+
+// IsFinal returns true if the target won't be called again on resume.
+func (s Status) IsFinal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusPlacing
+}
+
+// Result is the result of the campaign target.
+type Result struct {
+	TargetID       string                    `json:"target_id"`
+	ToNumber       string                    `json:"to_number"`
+	Status         Status                    `json:"status"`
+	CallID         string                    `json:"call_id,omitempty"`
+	CallStatus     vocode.CallStatus         `json:"call_status,omitempty"`
+	StageOutcome   vocode.CallStageOutcome   `json:"stage_outcome,omitempty"`
+	HumanDetection vocode.CallHumanDetection `json:"human_detection,omitempty"`
+	Error          string                    `json:"error,omitempty"`
+	StartedAt      time.Time                 `json:"started_at"`
+	EndedAt        time.Time                 `json:"ended_at,omitempty"`
+}
+
+// Options configure the campaign Runner.
+type Options struct {
+	// MaxConcurrent is the maximum number of calls in progress.
+	MaxConcurrent int
+	// CallsPerMinute limits the rate at which the calls are placed.
+	// There is no limit if it's not set.
+	CallsPerMinute int
+	// Limiter limits the rate at which the calls are placed.
+	// It takes precedence over CallsPerMinute.
+	Limiter client.Limiter
+	// Budget is the maximum number of call attempts made by the campaign
+	// including the attempts made by the runs it is resumed from, so
+	// the calls which failed to be placed count towards the budget too.
+	// There is no budget if it's not set.
+	Budget int
+	// Store persists the campaign progress.
+	Store Store
+	// Wait configures the tracking of the calls.
+	Wait *vocode.WaitOptions
+	// OnResult is called with the result of every target once it's known.
+	OnResult func(Result)
+	// Clock is used for timing the calls.
+	Clock vocode.Clock
+}
+
+// Option is functional campaign option.
+type Option func(*Options)
+
+// WithMaxConcurrent sets the maximum number of calls in progress.
+func WithMaxConcurrent(n int) Option {
+	return func(o *Options) {
+		o.MaxConcurrent = n
+	}
+}
+
+// WithCallsPerMinute limits the rate of the placed calls.
+func WithCallsPerMinute(n int) Option {
+	return func(o *Options) {
+		o.CallsPerMinute = n
+	}
+}
+
+// WithLimiter sets the placed calls rate limiter.
+func WithLimiter(l client.Limiter) Option {
+	return func(o *Options) {
+		o.Limiter = l
+	}
+}
+
+// WithBudget sets the maximum number of call attempts made by the campaign.
+func WithBudget(n int) Option {
+	return func(o *Options) {
+		o.Budget = n
+	}
+}
+
+// WithStore sets the campaign progress store.
+func WithStore(s Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// WithWaitOptions sets the call tracking options.
+func WithWaitOptions(w *vocode.WaitOptions) Option {
+	return func(o *Options) {
+		o.Wait = w
+	}
+}
+
+// WithOnResult sets the target result callback.
+func WithOnResult(fn func(Result)) Option {
+	return func(o *Options) {
+		o.OnResult = fn
+	}
+}
+
+// WithClock sets the campaign clock.
+func WithClock(c vocode.Clock) Option {
+	return func(o *Options) {
+		o.Clock = c
+	}
+}
+
+// Runner runs the outbound call campaign.
+type Runner struct {
+	client  *vocode.Client
+	call    vocode.CreateCallReq
+	opts    Options
+	limiter client.Limiter
+}
+
+// NewRunner creates a new campaign Runner and returns it.
+// The call request is the template of the campaign calls:
+// its ToNr is set to the target number and its Context
+// is merged with the target context.
+func NewRunner(c *vocode.Client, call vocode.CreateCallReq, opts ...Option) *Runner {
+	options := Options{
+		MaxConcurrent: DefaultMaxConcurrent,
+		Clock:         vocode.DefaultClock,
+	}
+	for _, apply := range opts {
+		apply(&options)
+	}
+	if options.MaxConcurrent < 1 {
+		options.MaxConcurrent = 1
+	}
+	if options.Store == nil {
+		options.Store = NewMemStore()
+	}
+
+	limiter := options.Limiter
+	if limiter == nil && options.CallsPerMinute > 0 {
+		limiter = NewPacer(options.CallsPerMinute, options.Clock)
+	}
+
+	return &Runner{
+		client:  c,
+		call:    call,
+		opts:    options,
+		limiter: limiter,
+	}
+}
+
+// Run calls all the targets streamed by src and tracks the calls until
+// they end. Targets whose final result is already stored are not called
+// again and the calls which were in progress when the previous run was
+// interrupted are tracked without being placed again. The calls which
+// could not be placed because of a retryable error are placed on resume.
+// Run returns the report once all the calls have ended, or when ctx is done,
+// or when the source fails, the API rejects the client credentials
+// or the usage guard reports the usage has been exhausted.
+func (r *Runner) Run(ctx context.Context, src Source) (*Report, error) {
+	prev, err := r.opts.Store.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load campaign progress: %w", err)
+	}
+	// NOTE: every stored result is the call attempt, whether the call
+	// was placed or not, because the targets skipped before their call
+	// was attempted are not stored.
+	attempts := len(prev)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		report  = &Report{}
+		slots   = make(chan struct{}, r.opts.MaxConcurrent)
+		srcErr  error
+		results = make(map[string]int)
+	)
+
+	// release returns the budget reserved for the call attempt
+	// which has been skipped before it was made.
+	release := func() {
+		mu.Lock()
+		attempts--
+		mu.Unlock()
+	}
+
+	// save persists the target result. It cancels the run if it fails.
+	save := func(res Result) error {
+		if err := r.opts.Store.Save(context.WithoutCancel(ctx), &res); err != nil {
+			err = fmt.Errorf("save campaign progress: %w", err)
+			cancel(err)
+			return err
+		}
+		return nil
+	}
+
+	// record updates the target result in the report and persists it.
+	record := func(res Result, persist bool) {
+		mu.Lock()
+		report.Results[results[res.TargetID]] = res
+		mu.Unlock()
+
+		if persist {
+			_ = save(res)
+		}
+		if res.Status != StatusCalling && r.opts.OnResult != nil {
+			r.opts.OnResult(res)
+		}
+	}
+
+loop:
+	for {
+		t, err := src.Next(ctx)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				srcErr = err
+			}
+			break
+		}
+		id := t.key()
+		if _, ok := results[id]; ok {
+			continue
+		}
+
+		// reserve the report slot so the results
+		// are reported in the source order
+		mu.Lock()
+		results[id] = len(report.Results)
+		report.Results = append(report.Results, Result{TargetID: id, ToNumber: t.ToNumber})
+		mu.Unlock()
+
+		res, ok := prev[id]
+		switch {
+		case ok && res.Status.IsFinal():
+			record(*res, false)
+			continue
+		case ok && res.Status == StatusCalling && res.CallID != "":
+			// resume tracking the call placed by the interrupted run
+		default:
+			mu.Lock()
+			exhausted := r.opts.Budget > 0 && attempts >= r.opts.Budget
+			if !exhausted {
+				attempts++
+			}
+			mu.Unlock()
+			if exhausted {
+				record(Result{TargetID: id, ToNumber: t.ToNumber, Status: StatusSkipped, Error: "budget exhausted"}, false)
+				continue
+			}
+			res = nil
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			if res == nil {
+				release()
+			}
+			record(Result{TargetID: id, ToNumber: t.ToNumber, Status: StatusSkipped}, false)
+			break loop
+		}
+
+		wg.Add(1)
+		go func(t Target, res *Result) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if res == nil {
+				call, err := r.place(ctx, t, save)
+				if errors.Is(err, vocode.ErrUnauthorized) || errors.Is(err, vocode.ErrUsageExhausted) {
+					// NOTE: no other call can be placed either
+					cancel(err)
+				}
+				switch {
+				case call.StartedAt.IsZero():
+					// the call has not been attempted
+					release()
+					record(call, false)
+					return
+				case call.Status == StatusPlacing:
+					// NOTE: the call might have been placed
+					// so its stored placing result is kept
+					record(call, false)
+					return
+				}
+				record(call, true)
+				if err != nil {
+					return
+				}
+				res = &call
+			}
+			record(r.track(ctx, *res), true)
+		}(t, res)
+	}
+
+	wg.Wait()
+
+	if cause := context.Cause(ctx); cause != nil {
+		return report, cause
+	}
+	return report, srcErr
+}
+
+// place places the call to the target. The placing result is saved
+// with save before the call is placed. It returns error if the call
+// could not be placed; the result StartedAt is zero if the call has
+// not been attempted and its status remains StatusPlacing if it is
+// not known whether the call has been placed.
+func (r *Runner) place(ctx context.Context, t Target, save func(Result) error) (Result, error) {
+	res := Result{
+		TargetID: t.key(),
+		ToNumber: t.ToNumber,
+		Status:   StatusSkipped,
+	}
+
+	if r.limiter != nil {
+		if err := r.limiter.Wait(ctx); err != nil {
+			return res, err
+		}
+	}
+
+	req := r.call
+	req.ToNr = t.ToNumber
+	if len(r.call.Context) > 0 || len(t.Context) > 0 {
+		req.Context = make(map[string]any, len(r.call.Context)+len(t.Context))
+		for k, v := range r.call.Context {
+			req.Context[k] = v
+		}
+		for k, v := range t.Context {
+			req.Context[k] = v
+		}
+	}
+
+	res.Status = StatusPlacing
+	res.StartedAt = r.opts.Clock.Now().UTC()
+	if err := save(res); err != nil {
+		res.Status = StatusSkipped
+		res.StartedAt = time.Time{}
+		return res, err
+	}
+
+	call, err := r.client.CreateCall(ctx, &req)
+	if err != nil {
+		var apiErr *vocode.APIError
+		switch {
+		case vocode.IsRetryable(err), errors.Is(err, vocode.ErrUnauthorized), errors.Is(err, vocode.ErrUsageExhausted):
+			// the call has not been placed but it can be on resume
+			res.Status = StatusSkipped
+		case errors.As(err, &apiErr):
+			res.Status = StatusFailed
+		default:
+			// NOTE: the request might have been sent before it
+			// failed e.g. if ctx was done while it was in flight
+			return res, err
+		}
+		res.Error = err.Error()
+		res.EndedAt = r.opts.Clock.Now().UTC()
+		return res, err
+	}
+
+	res.Status = StatusCalling
+	res.CallID = call.ID
+	res.CallStatus = call.Status
+	return res, nil
+}
+
+// track waits until the call placed to the target ends.
+// If ctx is done before the call ends the returned result
+// status remains StatusCalling so the call can be resumed.
+func (r *Runner) track(ctx context.Context, res Result) Result {
+	call, err := r.client.WaitForCall(ctx, res.CallID, r.opts.Wait)
+	if err != nil {
+		if ctx.Err() == nil {
+			res.Status = StatusFailed
+			res.Error = err.Error()
+			res.EndedAt = r.opts.Clock.Now().UTC()
+		}
+		return res
+	}
+
+	res.CallStatus = call.Status
+	res.StageOutcome = call.StageOutcome
+	res.HumanDetection = call.HumanDetection
+	res.EndedAt = r.opts.Clock.Now().UTC()
+	res.Status = StatusCompleted
+	if call.Status == vocode.CallError {
+		res.Status = StatusFailed
+		res.Error = call.ErrorMsg
+	}
+	return res
+}
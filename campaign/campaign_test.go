@@ -0,0 +1,343 @@
+package campaign
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/client"
+	"github.com/milosgajdos/go-vocode/vocodetest"
+)
+
+// fakeClock returns a fixed time, fires all the timers
+// immediately and records their durations.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// setup returns the server, its client and the campaign call template.
+func setup(t *testing.T) (*vocodetest.Server, *vocode.Client, vocode.CreateCallReq) {
+	t.Helper()
+	s := vocodetest.NewServer(vocodetest.WithAutoAdvance())
+	t.Cleanup(s.Close)
+	c := s.Client()
+	ctx := context.Background()
+
+	prompt, err := c.CreatePrompt(ctx, &vocode.CreatePromptReq{PromptReq: vocode.PromptReq{Content: "test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	voice, err := c.CreateVoice(ctx, &vocode.CreateVoiceReq{VoiceReq: vocode.VoiceReq{Type: vocode.RimeVoiceType, RimeVoice: &vocode.RimeVoice{Speaker: "test"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent, err := c.CreateAgent(ctx, &vocode.CreateAgentReq{AgentReq: vocode.AgentReq{Name: "test", Prompt: prompt.ID, Voice: voice.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	number, err := c.BuyNumber(ctx, &vocode.BuyNumberReq{AreaCode: "415", TelProvider: vocode.TwilioTelProvider})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, c, vocode.CreateCallReq{FromNr: number.Number, Agent: agent.ID, Context: map[string]any{"campaign": "test"}}
+}
+
+func targets() []Target {
+	return []Target{
+		{ToNumber: "+15550001", Context: map[string]any{"name": "Alice"}},
+		{ToNumber: "+15550002"},
+		{ToNumber: "+15550003"},
+		{ID: "dup", ToNumber: "+15550004"},
+		{ID: "dup", ToNumber: "+15550004"},
+	}
+}
+
+func waitOpts() *vocode.WaitOptions {
+	return &vocode.WaitOptions{Clock: &fakeClock{}}
+}
+
+func TestRunner(t *testing.T) {
+	t.Parallel()
+	t.Run("run", func(t *testing.T) {
+		t.Parallel()
+		s, c, call := setup(t)
+		s.SetCallScript("+15550002", vocodetest.CallScript{Outcome: vocode.CallStageDidNotConnect})
+		s.SetCallScript("+15550003", vocodetest.CallScript{Status: vocode.CallError, ErrorMsg: "boom"})
+
+		var (
+			mu      sync.Mutex
+			results []Result
+		)
+		r := NewRunner(c, call,
+			WithMaxConcurrent(2),
+			WithWaitOptions(waitOpts()),
+			WithOnResult(func(res Result) {
+				mu.Lock()
+				defer mu.Unlock()
+				results = append(results, res)
+			}),
+		)
+		report, err := r.Run(context.Background(), NewSliceSource(targets()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(report.Results) != 4 || len(results) != 4 {
+			t.Fatalf("expected 4 results, got: %d", len(report.Results))
+		}
+		if report.Count(StatusCompleted) != 3 || report.Count(StatusFailed) != 1 {
+			t.Fatalf("unexpected report: %+v", report.Results)
+		}
+		if res := report.Results[1]; res.StageOutcome != vocode.CallStageDidNotConnect {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+		if res := report.Results[2]; res.Status != StatusFailed || res.Error != "boom" {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+
+		got, err := c.GetCall(context.Background(), report.Results[0].CallID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := map[string]any{"campaign": "test", "name": "Alice"}; !reflect.DeepEqual(got.Context, exp) {
+			t.Fatalf("expected call context: %v, got: %v", exp, got.Context)
+		}
+
+		var buf bytes.Buffer
+		if err := report.WriteCSV(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(buf.String(), "\n"); lines != 5 {
+			t.Fatalf("expected 5 CSV lines, got: %d", lines)
+		}
+	})
+	t.Run("budget_resume", func(t *testing.T) {
+		t.Parallel()
+		_, c, call := setup(t)
+		path := filepath.Join(t.TempDir(), "progress.jsonl")
+
+		store, err := NewFileStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := NewRunner(c, call, WithBudget(2), WithStore(store), WithWaitOptions(waitOpts())).
+			Run(context.Background(), NewSliceSource(targets()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Count(StatusCompleted) != 2 || report.Count(StatusSkipped) != 2 {
+			t.Fatalf("unexpected report: %+v", report.Results)
+		}
+		store.Close()
+
+		store, err = NewFileStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+		report, err = NewRunner(c, call, WithStore(store), WithWaitOptions(waitOpts())).
+			Run(context.Background(), NewSliceSource(targets()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Count(StatusCompleted) != 4 {
+			t.Fatalf("unexpected report: %+v", report.Results)
+		}
+
+		calls, err := c.ListCalls(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls.Total != 4 {
+			t.Fatalf("expected 4 calls placed, got: %d", calls.Total)
+		}
+	})
+	t.Run("budget_failed", func(t *testing.T) {
+		t.Parallel()
+		_, c, call := setup(t)
+
+		// the call which failed to be placed counts towards the budget
+		store := NewMemStore()
+		failed := &Result{TargetID: "+15550001", ToNumber: "+15550001", Status: StatusFailed, Error: "boom"}
+		if err := store.Save(context.Background(), failed); err != nil {
+			t.Fatal(err)
+		}
+		report, err := NewRunner(c, call, WithBudget(2), WithStore(store), WithWaitOptions(waitOpts())).
+			Run(context.Background(), NewSliceSource(targets()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Count(StatusFailed) != 1 || report.Count(StatusCompleted) != 1 || report.Count(StatusSkipped) != 2 {
+			t.Fatalf("unexpected report: %+v", report.Results)
+		}
+	})
+	t.Run("placing_resume", func(t *testing.T) {
+		t.Parallel()
+		_, c, call := setup(t)
+
+		// the call which might have been placed is not placed again
+		store := NewMemStore()
+		placing := &Result{TargetID: "+15550001", ToNumber: "+15550001", Status: StatusPlacing}
+		if err := store.Save(context.Background(), placing); err != nil {
+			t.Fatal(err)
+		}
+		report, err := NewRunner(c, call, WithStore(store), WithWaitOptions(waitOpts())).
+			Run(context.Background(), NewSliceSource(targets()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Count(StatusPlacing) != 1 || report.Count(StatusCompleted) != 3 {
+			t.Fatalf("unexpected report: %+v", report.Results)
+		}
+
+		calls, err := c.ListCalls(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls.Total != 3 {
+			t.Fatalf("expected 3 calls placed, got: %d", calls.Total)
+		}
+	})
+	t.Run("retryable", func(t *testing.T) {
+		t.Parallel()
+		s, c, call := setup(t)
+		unavailable := s.Client(vocode.WithHTTPClient(client.NewHTTP(client.WithHTTPClient(&http.Client{
+			Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"detail":"unavailable"}`)),
+				}, nil
+			}),
+		}))))
+
+		store := NewMemStore()
+		report, err := NewRunner(unavailable, call, WithStore(store), WithWaitOptions(waitOpts())).
+			Run(context.Background(), NewSliceSource(targets()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Count(StatusSkipped) != 4 {
+			t.Fatalf("unexpected report: %+v", report.Results)
+		}
+		prev, err := store.Load(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res := prev["+15550001"]; res == nil || res.Status.IsFinal() || res.Error == "" {
+			t.Fatalf("expected non-final stored result, got: %+v", res)
+		}
+
+		report, err = NewRunner(c, call, WithStore(store), WithWaitOptions(waitOpts())).
+			Run(context.Background(), NewSliceSource(targets()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Count(StatusCompleted) != 4 {
+			t.Fatalf("unexpected report: %+v", report.Results)
+		}
+	})
+	t.Run("unauthorized", func(t *testing.T) {
+		t.Parallel()
+		s, _, call := setup(t)
+		c := s.Client(vocode.WithAPIKey("wrong"))
+
+		_, err := NewRunner(c, call, WithMaxConcurrent(1), WithWaitOptions(waitOpts())).
+			Run(context.Background(), NewSliceSource(targets()))
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestPacer(t *testing.T) {
+	t.Parallel()
+	clock := &fakeClock{now: time.Now()}
+	p := NewPacer(60, clock)
+	for i := 0; i < 3; i++ {
+		if err := p.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exp := []time.Duration{time.Second, 2 * time.Second}
+	if !reflect.DeepEqual(clock.waits, exp) {
+		t.Fatalf("expected waits: %v, got: %v", exp, clock.waits)
+	}
+}
+
+func TestCSVSource(t *testing.T) {
+	t.Parallel()
+	src, err := NewCSVSource(strings.NewReader("id,to_number,name\n1,+15550001,Alice\n2,+15550002,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []Target
+	for {
+		tg, err := src.Next(context.Background())
+		if err != nil {
+			break
+		}
+		got = append(got, tg)
+	}
+	exp := []Target{
+		{ID: "1", ToNumber: "+15550001", Context: map[string]any{"name": "Alice"}},
+		{ID: "2", ToNumber: "+15550002"},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("expected targets: %+v, got: %+v", exp, got)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "progress.jsonl")
+	// the process crashed while saving the second result
+	if err := os.WriteFile(path, []byte(`{"target_id":"a","status":"completed"}`+"\n"+`{"target_id":"b","sta`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := store.Save(ctx, &Result{TargetID: "c", Status: StatusCalling, CallID: "call-c"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the saved result is not appended to the partial one
+	results, err := store.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results["a"] == nil || results["c"] == nil || results["c"].CallID != "call-c" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
@@ -0,0 +1,53 @@
+package campaign
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// Pacer is client.Limiter which spaces
+// the calls evenly to the given rate per minute.
+type Pacer struct {
+	mu       sync.Mutex
+	clock    vocode.Clock
+	interval time.Duration
+	next     time.Time
+}
+
+// NewPacer creates a new Pacer allowing perMinute calls per minute and returns it.
+// If clock is nil, vocode.DefaultClock is used.
+func NewPacer(perMinute int, clock vocode.Clock) *Pacer {
+	if clock == nil {
+		clock = vocode.DefaultClock
+	}
+	return &Pacer{
+		clock:    clock,
+		interval: time.Minute / time.Duration(max(perMinute, 1)),
+	}
+}
+
+// Wait blocks until the next call is allowed or ctx is done.
+func (p *Pacer) Wait(ctx context.Context) error {
+	p.mu.Lock()
+	now := p.clock.Now()
+	at := p.next
+	if at.Before(now) {
+		at = now
+	}
+	p.next = at.Add(p.interval)
+	p.mu.Unlock()
+
+	d := at.Sub(now)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.clock.After(d):
+		return nil
+	}
+}
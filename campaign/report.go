@@ -0,0 +1,71 @@
+package campaign
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+)
+
+// Report is the campaign report.
+// It contains the result of every target in the order
+// the targets were streamed by the campaign source.
+type Report struct {
+	Results []Result
+}
+
+// Count returns the number of the targets with the given status.
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteCSV writes the report as CSV records to w.
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{
+		"target_id",
+		"to_number",
+		"status",
+		"call_id",
+		"call_status",
+		"stage_outcome",
+		"human_detection",
+		"error",
+		"started_at",
+		"ended_at",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, res := range r.Results {
+		rec := []string{
+			res.TargetID,
+			res.ToNumber,
+			string(res.Status),
+			res.CallID,
+			string(res.CallStatus),
+			string(res.StageOutcome),
+			string(res.HumanDetection),
+			res.Error,
+			formatTime(res.StartedAt),
+			formatTime(res.EndedAt),
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,131 @@
+package campaign
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Target is the campaign call target.
+type Target struct {
+	// ID uniquely identifies the target within the campaign.
+	// It defaults to ToNumber if it's empty.
+	ID string `json:"id"`
+	// ToNumber is the phone number to call.
+	ToNumber string `json:"to_number"`
+	// Context is passed to the call agent.
+	// It's merged with the campaign call context.
+	Context map[string]any `json:"context,omitempty"`
+}
+
+// key returns the target ID.
+func (t Target) key() string {
+	if t.ID != "" {
+		return t.ID
+	}
+	return t.ToNumber
+}
+
+// Source streams the campaign targets.
+type Source interface {
+	// Next returns the next target.
+	// It returns io.EOF when there are no more targets.
+	Next(ctx context.Context) (Target, error)
+}
+
+// SliceSource is a Source which streams the targets from a slice.
+type SliceSource struct {
+	targets []Target
+}
+
+// NewSliceSource creates a new SliceSource and returns it.
+func NewSliceSource(targets []Target) *SliceSource {
+	return &SliceSource{
+		targets: targets,
+	}
+}
+
+// Next implements Source.
+func (s *SliceSource) Next(ctx context.Context) (Target, error) {
+	if err := ctx.Err(); err != nil {
+		return Target{}, err
+	}
+	if len(s.targets) == 0 {
+		return Target{}, io.EOF
+	}
+	t := s.targets[0]
+	s.targets = s.targets[1:]
+	return t, nil
+}
+
+// CSVSource is a Source which streams the targets from CSV records.
+// The first CSV record is the header. The to_number column is required,
+// the id column is optional and all the other columns are added to the
+// target context keyed by their header names.
+type CSVSource struct {
+	r      *csv.Reader
+	header []string
+	number int
+	id     int
+}
+
+// NewCSVSource creates a new CSVSource reading the CSV records from r and returns it.
+func NewCSVSource(r io.Reader) (*CSVSource, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("missing CSV header")
+		}
+		return nil, err
+	}
+
+	s := &CSVSource{r: cr, header: header, number: -1, id: -1}
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "to_number":
+			s.number = i
+		case "id":
+			s.id = i
+		}
+	}
+	if s.number < 0 {
+		return nil, errors.New("missing to_number CSV column")
+	}
+	return s, nil
+}
+
+// Next implements Source.
+func (s *CSVSource) Next(ctx context.Context) (Target, error) {
+	if err := ctx.Err(); err != nil {
+		return Target{}, err
+	}
+	rec, err := s.r.Read()
+	if err != nil {
+		return Target{}, err
+	}
+
+	t := Target{ToNumber: rec[s.number]}
+	if s.id >= 0 {
+		t.ID = rec[s.id]
+	}
+	for i, v := range rec {
+		if i == s.number || i == s.id || v == "" {
+			continue
+		}
+		if t.Context == nil {
+			t.Context = make(map[string]any)
+		}
+		t.Context[s.header[i]] = v
+	}
+	if t.ToNumber == "" {
+		line, _ := s.r.FieldPos(s.number)
+		return Target{}, fmt.Errorf("line %d: empty to_number", line)
+	}
+	return t, nil
+}
@@ -0,0 +1,101 @@
+package campaign
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/milosgajdos/go-vocode/internal/jsonl"
+)
+
+// Store persists the campaign progress so that
+// an interrupted campaign can be resumed.
+type Store interface {
+	// Load returns the latest results of all the targets keyed by their ID.
+	Load(ctx context.Context) (map[string]*Result, error)
+	// Save stores the latest result of the target.
+	Save(ctx context.Context, r *Result) error
+}
+
+// MemStore is a Store which keeps the results in memory.
+type MemStore struct {
+	mu      sync.Mutex
+	results map[string]*Result
+}
+
+// NewMemStore creates a new MemStore and returns it.
+func NewMemStore() *MemStore {
+	return &MemStore{
+		results: make(map[string]*Result),
+	}
+}
+
+// Load implements Store.
+func (s *MemStore) Load(context.Context) (map[string]*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make(map[string]*Result, len(s.results))
+	for id, r := range s.results {
+		res := *r
+		results[id] = &res
+	}
+	return results, nil
+}
+
+// Save implements Store.
+func (s *MemStore) Save(_ context.Context, r *Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := *r
+	s.results[r.TargetID] = &res
+	return nil
+}
+
+// FileStore is a Store which appends every result
+// change to a JSONL file. When the file is loaded
+// the last result stored for the target wins.
+type FileStore struct {
+	log *jsonl.Log
+}
+
+// NewFileStore opens the JSONL file at path,
+// creating it if necessary, and returns the store.
+func NewFileStore(path string) (*FileStore, error) {
+	log, err := jsonl.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{
+		log: log,
+	}, nil
+}
+
+// Load implements Store.
+// It fails if the file contains a corrupt result.
+func (s *FileStore) Load(context.Context) (map[string]*Result, error) {
+	results := make(map[string]*Result)
+	err := s.log.Load(func(line []byte) error {
+		r := new(Result)
+		if err := json.Unmarshal(line, r); err != nil {
+			return err
+		}
+		results[r.TargetID] = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Save implements Store.
+func (s *FileStore) Save(_ context.Context, r *Result) error {
+	return s.log.Append(r)
+}
+
+// Close closes the store file.
+func (s *FileStore) Close() error {
+	return s.log.Close()
+}
@@ -2,19 +2,24 @@ package client
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"time"
 )
 
 // HTTP is an HTTP client.
 type HTTP struct {
 	client  *http.Client
 	limiter Limiter
+	retry   *RetryPolicy
 }
 
 // HTTPOptions configure the HTTP client.
 type HTTPOptions struct {
 	HTTPClient *http.Client
 	Limiter    Limiter
+	Recorder   *Recorder
+	Retry      *RetryPolicy
 }
 
 // HTTPOption is HTTP client functional option.
@@ -39,35 +44,82 @@ func DefaultTransport() *http.Transport {
 }
 
 // NewHTTP creates a new HTTP client and returns it.
+// By default the idempotent requests, such as GETs, are
+// retried according to the DefaultRetryPolicy.
 func NewHTTP(opts ...HTTPOption) *HTTP {
 	options := HTTPOptions{
 		HTTPClient: &http.Client{
 			Transport: DefaultTransport(),
 		},
+		Retry: DefaultRetryPolicy(),
 	}
 	for _, apply := range opts {
 		apply(&options)
 	}
 
+	if options.Recorder != nil {
+		// NOTE: we copy the client so we do not
+		// modify the one passed in via options.
+		c := *options.HTTPClient
+		c.Transport = options.Recorder.Transport(c.Transport)
+		options.HTTPClient = &c
+	}
+
 	return &HTTP{
 		client:  options.HTTPClient,
 		limiter: options.Limiter,
+		retry:   options.Retry,
 	}
 }
 
 // Do dispatches the HTTP request to the remote endpoint.
+// Unless the retries have been disabled, the requests failing
+// with network errors or the retryable status codes
+// are retried according to the policy.
 func (h *HTTP) Do(req *http.Request) (*http.Response, error) {
-	if h.limiter != nil {
-		err := h.limiter.Wait(req.Context()) // This is a blocking call which honors the rate limit
+	retry := h.retry.canRetry(req)
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		if h.limiter != nil {
+			err := h.limiter.Wait(req.Context()) // This is a blocking call which honors the rate limit
+			if err != nil {
+				return nil, err
+			}
+		}
+		resp, err := h.client.Do(req)
+		if !retry || attempt >= h.retry.MaxAttempts {
+			return resp, err
+		}
+
+		wait := h.retry.backoff(attempt)
 		if err != nil {
+			// NOTE: the replayed interactions do not change between the attempts
+			if req.Context().Err() != nil || errors.Is(err, ErrNoInteraction) {
+				return nil, err
+			}
+		} else {
+			if !h.retry.retryStatus(resp.StatusCode) {
+				return resp, nil
+			}
+			if d, ok := retryAfter(resp, time.Now()); ok {
+				if h.retry.MaxRetryAfter > 0 && d > h.retry.MaxRetryAfter {
+					return resp, nil
+				}
+				wait = max(wait, d)
+			}
+			drain(resp)
+		}
+
+		if err := sleep(req.Context(), wait); err != nil {
 			return nil, err
 		}
 	}
-	resp, err := h.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	return resp, nil
 }
 
 // WithHTTPClient sets the HTTP client to c.
@@ -83,3 +135,19 @@ func WithLimiter(l Limiter) HTTPOption {
 		o.Limiter = l
 	}
 }
+
+// WithRecorder records the HTTP interactions with r
+// or replays them from it depending on its mode.
+func WithRecorder(r *Recorder) HTTPOption {
+	return func(o *HTTPOptions) {
+		o.Recorder = r
+	}
+}
+
+// WithRetryPolicy sets the retry policy of the failed requests.
+// It replaces the DefaultRetryPolicy; nil policy disables retries.
+func WithRetryPolicy(p *RetryPolicy) HTTPOption {
+	return func(o *HTTPOptions) {
+		o.Retry = p
+	}
+}
@@ -0,0 +1,454 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+)
+
+const (
+	// Redacted replaces the redacted header and field values.
+	Redacted = "REDACTED"
+)
+
+var (
+	// ErrNoInteraction is returned when replaying a request
+	// that has no matching interaction in the cassette.
+	ErrNoInteraction = errors.New("no matching interaction")
+)
+
+// RecorderMode is the Recorder mode.
+type RecorderMode int
+
+const (
+	// ModeRecord sends the requests to the remote endpoint
+	// and records the interactions in the cassette.
+	ModeRecord RecorderMode = iota
+	// ModeReplay replays the interactions from the cassette
+	// without sending any requests to the remote endpoint.
+	ModeReplay
+)
+
+// MatchMode controls how the replayed requests are matched.
+type MatchMode int
+
+const (
+	// StrictMatch requires the requests to be sent in the recorded
+	// order and match the recorded method, path, query and body.
+	StrictMatch MatchMode = iota
+	// LenientMatch matches the first unused interaction with the same
+	// method, path and query; the request body is ignored. If all the
+	// matching interactions have been used the last one is replayed.
+	LenientMatch
+)
+
+// DefaultRedactHeaders are the headers redacted by default.
+var DefaultRedactHeaders = []string{"Authorization"}
+
+// DefaultRedactFields are the JSON body fields redacted by default.
+var DefaultRedactFields = []string{"api_key", "openai_api_key", "twilio_auth_token"}
+
+// Cassette stores the recorded interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is the recorded request and response pair.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the recorded HTTP request.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// RecordedResponse is the recorded HTTP response.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitempty"`
+}
+
+// Body is the recorded HTTP body.
+// JSON bodies are stored verbatim so they can be diffed;
+// any other body is stored base64 encoded.
+type Body []byte
+
+// MarshalJSON implements json.Marshaler.
+func (b Body) MarshalJSON() ([]byte, error) {
+	if len(b) == 0 {
+		return []byte("null"), nil
+	}
+	if json.Valid(b) {
+		return b, nil
+	}
+	return json.Marshal(map[string][]byte{"base64": b})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Body) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*b = nil
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err == nil && len(raw) == 1 && raw["base64"] != nil {
+		var bin []byte
+		if err := json.Unmarshal(raw["base64"], &bin); err != nil {
+			return err
+		}
+		*b = bin
+		return nil
+	}
+	// NOTE: cassettes are stored indented
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return err
+	}
+	*b = buf.Bytes()
+	return nil
+}
+
+// Recorder records the HTTP interactions in a cassette
+// file and replays them back from it.
+type Recorder struct {
+	path          string
+	opts          RecorderOptions
+	redactHeaders map[string]bool
+	redactFields  map[string]bool
+
+	mu   sync.Mutex
+	tape Cassette
+	used []bool
+	next int
+}
+
+// RecorderOptions configure the Recorder.
+type RecorderOptions struct {
+	Mode          RecorderMode
+	Match         MatchMode
+	RedactHeaders []string
+	RedactFields  []string
+}
+
+// RecorderOption is Recorder functional option.
+type RecorderOption func(*RecorderOptions)
+
+// NewRecorder creates a new Recorder using the cassette file at path and returns it.
+// In ModeReplay the cassette file is read and must exist. In ModeRecord
+// the cassette file is (re)written every time a new interaction is recorded.
+func NewRecorder(path string, opts ...RecorderOption) (*Recorder, error) {
+	options := RecorderOptions{
+		Mode:          ModeRecord,
+		Match:         StrictMatch,
+		RedactHeaders: DefaultRedactHeaders,
+		RedactFields:  DefaultRedactFields,
+	}
+	for _, apply := range opts {
+		apply(&options)
+	}
+
+	r := &Recorder{
+		path:          path,
+		opts:          options,
+		redactHeaders: make(map[string]bool),
+		redactFields:  make(map[string]bool),
+	}
+	for _, h := range options.RedactHeaders {
+		r.redactHeaders[http.CanonicalHeaderKey(h)] = true
+	}
+	for _, f := range options.RedactFields {
+		r.redactFields[f] = true
+	}
+
+	if options.Mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &r.tape); err != nil {
+			return nil, fmt.Errorf("decode cassette %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.tape.Interactions))
+	}
+
+	return r, nil
+}
+
+// WithRecorderMode sets the Recorder mode.
+func WithRecorderMode(mode RecorderMode) RecorderOption {
+	return func(o *RecorderOptions) {
+		o.Mode = mode
+	}
+}
+
+// WithMatchMode sets the replayed request matching mode.
+func WithMatchMode(match MatchMode) RecorderOption {
+	return func(o *RecorderOptions) {
+		o.Match = match
+	}
+}
+
+// WithRedactHeaders sets the headers redacted in the cassette.
+func WithRedactHeaders(headers ...string) RecorderOption {
+	return func(o *RecorderOptions) {
+		o.RedactHeaders = headers
+	}
+}
+
+// WithRedactFields sets the JSON body fields redacted in the cassette.
+func WithRedactFields(fields ...string) RecorderOption {
+	return func(o *RecorderOptions) {
+		o.RedactFields = fields
+	}
+}
+
+// Interactions returns the interactions in the cassette.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	interactions := make([]Interaction, len(r.tape.Interactions))
+	copy(interactions, r.tape.Interactions)
+	return interactions
+}
+
+// Transport returns http.RoundTripper which records or replays
+// the requests depending on the Recorder mode. The recorded
+// requests are sent to the remote endpoint via base.
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &recorderTransport{rec: r, base: base}
+}
+
+type recorderTransport struct {
+	rec  *Recorder
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *recorderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.rec.opts.Mode == ModeReplay {
+		return t.rec.replay(req)
+	}
+	return t.rec.record(req, t.base)
+}
+
+func (r *Recorder) record(req *http.Request, base http.RoundTripper) (*http.Response, error) {
+	reqBody, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: http.RoundTripper must not modify the request
+	// so we send its clone with the body we have read.
+	out := req.Clone(req.Context())
+	if reqBody != nil {
+		out.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := base.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	i := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: r.redactHeader(req.Header),
+			Body:   r.redactBody(reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     r.redactHeader(resp.Header),
+			Body:       r.redactBody(respBody),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tape.Interactions = append(r.tape.Interactions, i)
+	if err := r.save(); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// save atomically writes the cassette to its file.
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.tape, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	body = r.redactBody(body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := -1
+	switch r.opts.Match {
+	case StrictMatch:
+		if r.next < len(r.tape.Interactions) && matchStrict(req, body, &r.tape.Interactions[r.next].Request) {
+			idx = r.next
+			r.next++
+		}
+	case LenientMatch:
+		for i := range r.tape.Interactions {
+			if !matchLenient(req, &r.tape.Interactions[i].Request) {
+				continue
+			}
+			idx = i
+			if !r.used[i] {
+				break
+			}
+		}
+	}
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL)
+	}
+	r.used[idx] = true
+
+	rec := r.tape.Interactions[idx].Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(rec.Body)),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}, nil
+}
+
+// matchLenient matches the request method, path and query.
+// The recorded host is ignored so the cassettes can be replayed
+// against a different base URL than they were recorded with.
+func matchLenient(req *http.Request, rec *RecordedRequest) bool {
+	if req.Method != rec.Method {
+		return false
+	}
+	u, err := req.URL.Parse(rec.URL)
+	if err != nil {
+		return false
+	}
+	return u.Path == req.URL.Path && reflect.DeepEqual(u.Query(), req.URL.Query())
+}
+
+// matchStrict matches the request method, path, query and body.
+// JSON bodies are compared semantically.
+func matchStrict(req *http.Request, body []byte, rec *RecordedRequest) bool {
+	if !matchLenient(req, rec) {
+		return false
+	}
+	if len(body) == 0 || len(rec.Body) == 0 {
+		return len(body) == len(rec.Body)
+	}
+	var got, exp any
+	if json.Unmarshal(body, &got) != nil || json.Unmarshal(rec.Body, &exp) != nil {
+		return bytes.Equal(body, rec.Body)
+	}
+	return reflect.DeepEqual(got, exp)
+}
+
+// readBody reads the whole body and closes it.
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil || body == http.NoBody {
+		return nil, nil
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+func (r *Recorder) redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for k := range h {
+		if r.redactHeaders[k] {
+			h[k] = []string{Redacted}
+		}
+	}
+	return h
+}
+
+// redactBody redacts the configured fields in the JSON body.
+// Non-JSON bodies are returned unchanged.
+func (r *Recorder) redactBody(body []byte) []byte {
+	var v any
+	if len(body) == 0 || json.Unmarshal(body, &v) != nil {
+		return body
+	}
+	if !r.redactValue(v) {
+		return body
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return data
+}
+
+// redactValue redacts the configured fields found anywhere in v
+// and reports whether any field has been redacted.
+func (r *Recorder) redactValue(v any) bool {
+	redacted := false
+	switch val := v.(type) {
+	case map[string]any:
+		for k, fv := range val {
+			if r.redactFields[k] && fv != nil {
+				val[k] = Redacted
+				redacted = true
+				continue
+			}
+			redacted = r.redactValue(fv) || redacted
+		}
+	case []any:
+		for _, item := range val {
+			redacted = r.redactValue(item) || redacted
+		}
+	}
+	return redacted
+}
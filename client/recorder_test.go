@@ -0,0 +1,114 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"` + r.URL.Path + `","api_key":"secret","echo":` + string(body) + `}`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func send(t *testing.T, c *HTTP, method, url, body string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	return c.Do(req)
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	rec, err := NewRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewHTTP(WithRecorder(rec))
+	for _, body := range []string{`{"n":1}`, `{"n":2}`} {
+		resp, err := send(t, c, http.MethodPost, ts.URL+"/v1/prompts/create?x=1", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !bytes.Contains(data, []byte(`"api_key":"secret"`)) {
+			t.Fatalf("expected unredacted response body, got: %s", data)
+		}
+	}
+
+	cassette, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(cassette, []byte("secret")) {
+		t.Fatalf("expected redacted cassette, got: %s", cassette)
+	}
+
+	t.Run("strict", func(t *testing.T) {
+		t.Parallel()
+		rec, err := NewRecorder(path, WithRecorderMode(ModeReplay))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := NewHTTP(WithRecorder(rec))
+
+		// replayed requests ignore the recorded host
+		resp, err := send(t, c, http.MethodPost, "http://example.com/v1/prompts/create?x=1", `{"n": 1}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if exp := `{"api_key":"REDACTED","echo":{"n":1},"path":"/v1/prompts/create"}`; string(data) != exp {
+			t.Fatalf("expected body: %s, got: %s", exp, data)
+		}
+
+		if _, err := send(t, c, http.MethodPost, ts.URL+"/v1/prompts/create?x=1", `{"n":3}`); !errors.Is(err, ErrNoInteraction) {
+			t.Fatalf("expected error: %v, got: %v", ErrNoInteraction, err)
+		}
+	})
+	t.Run("lenient", func(t *testing.T) {
+		t.Parallel()
+		rec, err := NewRecorder(path, WithRecorderMode(ModeReplay), WithMatchMode(LenientMatch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := NewHTTP(WithRecorder(rec))
+
+		for _, exp := range []string{`{"n":1}`, `{"n":2}`, `{"n":2}`} {
+			resp, err := send(t, c, http.MethodPost, ts.URL+"/v1/prompts/create?x=1", `{}`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if !bytes.Contains(data, []byte(exp)) {
+				t.Fatalf("expected body to contain: %s, got: %s", exp, data)
+			}
+		}
+
+		if _, err := send(t, c, http.MethodGet, ts.URL+"/v1/prompts/create?x=1", ""); !errors.Is(err, ErrNoInteraction) {
+			t.Fatalf("expected error: %v, got: %v", ErrNoInteraction, err)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts is the default maximum number of request attempts.
+	DefaultMaxAttempts = 4
+	// DefaultMinBackoff is the default backoff before the first retry.
+	DefaultMinBackoff = 500 * time.Millisecond
+	// DefaultMaxBackoff is the default maximum backoff between retries.
+	DefaultMaxBackoff = 30 * time.Second
+	// DefaultMaxRetryAfter is the default maximum Retry-After delay honored.
+	DefaultMaxRetryAfter = time.Minute
+)
+
+// DefaultRetryStatuses are the HTTP status codes retried by default.
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy configures the retries of the failed requests.
+// Requests are retried when they fail with a network error or
+// with one of the RetryStatuses. Only the requests with idempotent
+// methods are retried unless RetryNonIdempotent is enabled
+// or the request context has been marked with AllowRetry.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts
+	// including the first one. Values < 2 disable retries.
+	MaxAttempts int
+	// MinBackoff is the backoff before the first retry.
+	// It doubles with every subsequent retry.
+	MinBackoff time.Duration
+	// MaxBackoff caps the exponential backoff.
+	MaxBackoff time.Duration
+	// Jitter is the fraction of the backoff randomized
+	// to spread the retries. It must be within [0, 1].
+	Jitter float64
+	// MaxRetryAfter is the longest Retry-After delay honored.
+	// Responses asking for a longer delay are not retried.
+	MaxRetryAfter time.Duration
+	// RetryStatuses are the retried HTTP status codes.
+	RetryStatuses []int
+	// RetryNonIdempotent enables retries of all the requests
+	// regardless of their method e.g. POST requests.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the default retry policy.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:   DefaultMaxAttempts,
+		MinBackoff:    DefaultMinBackoff,
+		MaxBackoff:    DefaultMaxBackoff,
+		Jitter:        0.5,
+		MaxRetryAfter: DefaultMaxRetryAfter,
+		RetryStatuses: DefaultRetryStatuses,
+	}
+}
+
+type allowRetryKey struct{}
+
+// AllowRetry returns a copy of ctx which allows retrying
+// the non-idempotent requests sent with it.
+// Use it only for requests that are safe to repeat.
+func AllowRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowRetryKey{}, true)
+}
+
+// canRetry returns true if the request can be retried.
+func (p *RetryPolicy) canRetry(req *http.Request) bool {
+	if p == nil || p.MaxAttempts < 2 {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// NOTE: we can't rewind the body
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions,
+		http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	}
+	if p.RetryNonIdempotent {
+		return true
+	}
+	allow, _ := req.Context().Value(allowRetryKey{}).(bool)
+	return allow
+}
+
+// retryStatus returns true if the response status code is retried.
+func (p *RetryPolicy) retryStatus(code int) bool {
+	for _, c := range p.RetryStatuses {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the jittered exponential backoff before the given retry.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	d := float64(p.MinBackoff) * math.Pow(2, float64(retry-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d -= d * p.Jitter * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// retryAfter parses the Retry-After response header.
+// It supports both the delay in seconds and the HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	val := resp.Header.Get("Retry-After")
+	if val == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(val); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(val); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// drain discards the rest of the response body and closes it
+// so the underlying connection can be reused.
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
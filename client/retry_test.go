@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures requests with status and
+// records the request bodies of all the requests it receives.
+func flakyServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *atomic.Int32, chan string) {
+	t.Helper()
+	calls := new(atomic.Int32)
+	bodies := make(chan string, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+		if calls.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, calls, bodies
+}
+
+func testPolicy() *RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.MinBackoff = time.Millisecond
+	p.MaxBackoff = 5 * time.Millisecond
+	return p
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+	t.Run("get", func(t *testing.T) {
+		t.Parallel()
+		ts, calls, _ := flakyServer(t, 2, http.StatusBadGateway, nil)
+		c := NewHTTP(WithRetryPolicy(testPolicy()))
+
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+			t.Fatalf("expected status %d after 3 attempts, got: %d after %d", http.StatusOK, resp.StatusCode, calls.Load())
+		}
+	})
+	t.Run("max_attempts", func(t *testing.T) {
+		t.Parallel()
+		ts, calls, _ := flakyServer(t, 10, http.StatusServiceUnavailable, nil)
+		c := NewHTTP(WithRetryPolicy(testPolicy()))
+
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != DefaultMaxAttempts {
+			t.Fatalf("expected %d attempts, got: %d", DefaultMaxAttempts, calls.Load())
+		}
+	})
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+		ts, calls, _ := flakyServer(t, 1, http.StatusBadGateway, nil)
+		c := NewHTTP()
+
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+			t.Fatalf("expected status %d after 2 attempts, got: %d after %d", http.StatusOK, resp.StatusCode, calls.Load())
+		}
+	})
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		ts, calls, _ := flakyServer(t, 1, http.StatusBadGateway, nil)
+		c := NewHTTP(WithRetryPolicy(nil))
+
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadGateway || calls.Load() != 1 {
+			t.Fatalf("expected single attempt, got: %d", calls.Load())
+		}
+	})
+	t.Run("post", func(t *testing.T) {
+		t.Parallel()
+		ts, calls, _ := flakyServer(t, 1, http.StatusBadGateway, nil)
+		c := NewHTTP(WithRetryPolicy(testPolicy()))
+
+		req, _ := http.NewRequest(http.MethodPost, ts.URL, bytes.NewBufferString("body"))
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadGateway || calls.Load() != 1 {
+			t.Fatalf("expected single attempt, got: %d", calls.Load())
+		}
+	})
+	t.Run("post_allowed", func(t *testing.T) {
+		t.Parallel()
+		ts, calls, bodies := flakyServer(t, 1, http.StatusBadGateway, nil)
+		c := NewHTTP(WithRetryPolicy(testPolicy()))
+
+		req, _ := http.NewRequestWithContext(AllowRetry(context.Background()), http.MethodPost, ts.URL, bytes.NewBufferString("body"))
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+			t.Fatalf("expected status %d after 2 attempts, got: %d after %d", http.StatusOK, resp.StatusCode, calls.Load())
+		}
+		for i := 0; i < 2; i++ {
+			if body := <-bodies; body != "body" {
+				t.Fatalf("expected rewound body: %q, got: %q", "body", body)
+			}
+		}
+	})
+	t.Run("retry_after", func(t *testing.T) {
+		t.Parallel()
+		header := http.Header{"Retry-After": []string{"1"}}
+		ts, calls, _ := flakyServer(t, 1, http.StatusTooManyRequests, header)
+		c := NewHTTP(WithRetryPolicy(testPolicy()))
+
+		start := time.Now()
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+			t.Fatalf("expected status %d after 2 attempts, got: %d after %d", http.StatusOK, resp.StatusCode, calls.Load())
+		}
+		if elapsed := time.Since(start); elapsed < time.Second {
+			t.Fatalf("expected Retry-After delay, got: %v", elapsed)
+		}
+	})
+	t.Run("retry_after_too_long", func(t *testing.T) {
+		t.Parallel()
+		header := http.Header{"Retry-After": []string{"3600"}}
+		ts, calls, _ := flakyServer(t, 1, http.StatusTooManyRequests, header)
+		c := NewHTTP(WithRetryPolicy(testPolicy()))
+
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 {
+			t.Fatalf("expected single attempt, got: %d", calls.Load())
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+var callCols = []column[vocode.Call]{
+	{"ID", func(c *vocode.Call) string { return c.ID }},
+	{"STATUS", func(c *vocode.Call) string { return string(c.Status) }},
+	{"FROM", func(c *vocode.Call) string { return c.FromNumber }},
+	{"TO", func(c *vocode.Call) string { return c.ToNumber }},
+	{"AGENT", func(c *vocode.Call) string {
+		if c.Agent == nil {
+			return ""
+		}
+		return c.Agent.ID
+	}},
+	{"START", func(c *vocode.Call) string { return c.StartTime }},
+}
+
+func callsGroup() *group {
+	return &group{
+		name:  "calls",
+		short: "manage calls",
+		commands: []*command{
+			{name: "list", short: "list calls", run: func(ctx context.Context, a *app, args []string) error {
+				return listCmd(ctx, a, "calls list", args, (*vocode.Client).AllCalls, callCols)
+			}},
+			{name: "get", args: "<id>", short: "get the call", run: getCall},
+			{name: "create", args: "[-f file]", short: "create the call", run: createCall},
+			{name: "end", args: "<id>", short: "end the call", run: endCall},
+			{name: "recording", args: "<id> [path]", short: "download the call recording", run: downloadRecording},
+		},
+	}
+}
+
+func getCall(ctx context.Context, a *app, args []string) error {
+	id, err := a.parseID("calls get", args)
+	if err != nil {
+		return err
+	}
+	call, err := a.client.GetCall(ctx, id)
+	if err != nil {
+		return err
+	}
+	return writeItem(a, call, callCols)
+}
+
+func createCall(ctx context.Context, a *app, args []string) error {
+	req := &vocode.CreateCallReq{}
+	rest, err := parseInput(a, "calls create", args, req, func(fs *flag.FlagSet, r *vocode.CreateCallReq) {
+		fs.StringVar(&r.FromNr, "from", r.FromNr, "agent phone `number`")
+		fs.StringVar(&r.ToNr, "to", r.ToNr, "called phone `number`")
+		fs.StringVar(&r.Agent, "agent", r.Agent, "agent `ID`")
+		fs.Var(stringFlag[vocode.CallOnNoHumanAnswer]{&r.OnHumanNoAnswer}, "on-no-human-answer", "`action` when no human answers: continue or hangup")
+		fs.BoolVar(&r.RunDNC, "run-dnc", r.RunDNC, "run do not call detection")
+		fs.BoolVar(&r.HIPAACompliant, "hipaa", r.HIPAACompliant, "make the call HIPAA compliant")
+	})
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	call, err := a.client.CreateCall(ctx, req)
+	if err != nil {
+		return err
+	}
+	return writeItem(a, call, callCols)
+}
+
+func endCall(ctx context.Context, a *app, args []string) error {
+	id, err := a.parseID("calls end", args)
+	if err != nil {
+		return err
+	}
+	call, err := a.client.EndCall(ctx, id)
+	if err != nil {
+		return err
+	}
+	return writeItem(a, call, callCols)
+}
+
+var recordingCols = []column[vocode.Recording]{
+	{"PATH", func(r *vocode.Recording) string { return r.Path }},
+	{"FORMAT", func(r *vocode.Recording) string { return string(r.Format) }},
+	{"SIZE", func(r *vocode.Recording) string { return fmt.Sprint(r.Size) }},
+	{"SHA256", func(r *vocode.Recording) string { return r.SHA256 }},
+}
+
+// downloadRecording downloads the recording to the path
+// which defaults to the call ID in the current directory.
+func downloadRecording(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("calls recording")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return fmt.Errorf("usage: calls recording <id> [path]")
+	}
+	id, path := fs.Arg(0), fs.Arg(0)
+	if fs.NArg() == 2 {
+		path = fs.Arg(1)
+	}
+	rec, err := a.client.DownloadRecording(ctx, id, path)
+	if err != nil {
+		return err
+	}
+	return writeItem(a, rec, recordingCols)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// config is the CLI config file.
+// See vocode.Config for its format.
+type config = vocode.Config
+
+// profile configures the API client.
+type profile = vocode.Profile
+
+// clientOptions returns the client options of the named profile.
+// Without the profile the API key is read from VOCODE_API_KEY
+// and if it's not set the default profile is used.
+func clientOptions(cfg *config, name string, getenv func(string) string) ([]vocode.Option, error) {
+	if name == "" {
+		if key := getenv("VOCODE_API_KEY"); key != "" {
+			return []vocode.Option{vocode.WithAPIKey(key)}, nil
+		}
+		if cfg != nil {
+			name = cfg.Profile
+		}
+		if name == "" {
+			return nil, errors.New("missing API key: set VOCODE_API_KEY or configure a profile")
+		}
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("profile %q: no config file", name)
+	}
+	return cfg.Options(name)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// parseInput parses the command args into req. The request is read from
+// the JSON or YAML file set by the -f flag, if any, and the other flags
+// set by bind override its fields. It returns the remaining args.
+func parseInput[R any](a *app, name string, args []string, req *R, bind func(*flag.FlagSet, *R)) ([]string, error) {
+	fs := a.flagSet(name)
+	file := fs.String("f", "", "read the request from JSON or YAML `file` (- for stdin)")
+	scratch := new(R)
+	if bind != nil {
+		bind(fs, scratch)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *file != "" {
+		if err := a.readFile(*file, req); err != nil {
+			return nil, err
+		}
+	}
+
+	// NOTE: the flags are parsed into scratch first so they can
+	// be applied on top of the request read from the file.
+	target := flag.NewFlagSet(name, flag.ContinueOnError)
+	if bind != nil {
+		bind(target, req)
+	}
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		if err != nil || target.Lookup(f.Name) == nil {
+			return
+		}
+		err = target.Set(f.Name, f.Value.String())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fs.Args(), nil
+}
+
+// readFile decodes the JSON or YAML file into v.
+// The format of stdin is detected from its content.
+func (a *app) readFile(path string, v any) error {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(a.stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	isJSON := false
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		isJSON = true
+	case ".yaml", ".yml":
+	default:
+		trimmed := bytes.TrimSpace(data)
+		isJSON = len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+	}
+
+	if isJSON {
+		err = json.Unmarshal(data, v)
+	} else {
+		err = yaml.Unmarshal(data, v)
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	return nil
+}
+
+// stringFlag is the flag.Value of the string type T.
+type stringFlag[T ~string] struct {
+	v *T
+}
+
+func (f stringFlag[T]) Set(s string) error {
+	*f.v = T(s)
+	return nil
+}
+
+func (f stringFlag[T]) String() string {
+	if f.v == nil {
+		return ""
+	}
+	return string(*f.v)
+}
+
+// listFlag is the flag.Value of the comma separated list of the string type T.
+type listFlag[T ~string] struct {
+	v *[]T
+}
+
+func (f listFlag[T]) Set(s string) error {
+	*f.v = nil
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*f.v = append(*f.v, T(item))
+		}
+	}
+	return nil
+}
+
+func (f listFlag[T]) String() string {
+	if f.v == nil {
+		return ""
+	}
+	items := make([]string, len(*f.v))
+	for i, item := range *f.v {
+		items[i] = string(item)
+	}
+	return strings.Join(items, ",")
+}
+
+// funcFlag is the flag.Value which sets and gets the value via functions.
+type funcFlag struct {
+	set func(string)
+	get func() string
+}
+
+func (f funcFlag) Set(s string) error {
+	f.set(s)
+	return nil
+}
+
+func (f funcFlag) String() string {
+	if f.get == nil {
+		return ""
+	}
+	return f.get()
+}
@@ -0,0 +1,217 @@
+// Command vocode manages the Vocode account resources.
+//
+// Usage:
+//
+//	vocode [-profile name] [-config file] [-output table|json|yaml] <resource> <command> [flags] [args]
+//
+// The resources are agents, prompts, voices, actions, webhooks, vector-dbs,
+// account-connections, calls and numbers; usage prints the account usage.
+// The requests are read from the command flags or from the JSON or YAML
+// file passed via the -f flag, e.g.
+//
+//	vocode agents create -f agent.yaml
+//	vocode agents update <id> -name Support -voice <voice-id>
+//	vocode -output json calls get <id>
+//
+// The API key is read from VOCODE_API_KEY or from the profile in the config
+// file which defaults to vocode/config.json in the user config directory.
+// See vocode.Config for the config file format.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// app is the command environment.
+type app struct {
+	client *vocode.Client
+	format Format
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is the resource command.
+type command struct {
+	name  string
+	args  string
+	short string
+	run   func(ctx context.Context, a *app, args []string) error
+}
+
+// group groups the commands of the resource. The group
+// which has no commands runs its run func directly.
+type group struct {
+	name     string
+	short    string
+	commands []*command
+	run      func(ctx context.Context, a *app, args []string) error
+}
+
+func groups() []*group {
+	return []*group{
+		agents.group(),
+		prompts.group(),
+		voices.group(),
+		actions.group(),
+		webhooks.group(),
+		vectorDBs.group(),
+		accountConns.group(),
+		callsGroup(),
+		numbersGroup(),
+		{name: "usage", short: "show the account usage", run: showUsage},
+	}
+}
+
+var usageCols = []column[vocode.Usage]{
+	{"PLAN", func(u *vocode.Usage) string { return string(u.PlanType) }},
+	{"MINUTES", func(u *vocode.Usage) string { return fmt.Sprint(u.MonthlyMinutes) }},
+	{"LIMIT", func(u *vocode.Usage) string { return fmt.Sprint(u.MonthlyLimitMinutes) }},
+}
+
+func showUsage(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("usage")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	u, err := a.client.GetUsage(ctx)
+	if err != nil {
+		return err
+	}
+	return writeItem(a, u, usageCols)
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	a := &app{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+	if err := run(ctx, a, os.Args[1:], os.Getenv); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "vocode:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run parses the global flags and runs the command.
+func run(ctx context.Context, a *app, args []string, getenv func(string) string) error {
+	fs := a.flagSet("vocode")
+	profile := fs.String("profile", getenv("VOCODE_PROFILE"), "config `profile` name")
+	// NOTE: there is no default config file without the user config dir
+	defaultPath, _ := vocode.DefaultConfigPath()
+	cfgPath := fs.String("config", defaultPath, "config `file`")
+	a.format = TableFormat
+	fs.Var(&a.format, "output", "output `format`: table, json or yaml")
+	fs.Usage = func() { a.usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 || fs.Arg(0) == "help" {
+		a.usage(fs)
+		return nil
+	}
+	g, cmd, cmdArgs, err := lookup(fs.Args())
+	if err != nil {
+		a.usage(fs)
+		return err
+	}
+
+	var cfg *config
+	if *cfgPath != "" {
+		cfg, err = vocode.LoadConfig(*cfgPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	opts, err := clientOptions(cfg, *profile, getenv)
+	if err != nil {
+		return err
+	}
+	a.client = vocode.NewClient(opts...)
+
+	if cmd == nil {
+		return g.run(ctx, a, cmdArgs)
+	}
+	return cmd.run(ctx, a, cmdArgs)
+}
+
+// lookup returns the group and the command named by args.
+// The command is nil if the group has no commands.
+func lookup(args []string) (*group, *command, []string, error) {
+	for _, g := range groups() {
+		if g.name != args[0] {
+			continue
+		}
+		if g.run != nil {
+			return g, nil, args[1:], nil
+		}
+		if len(args) < 2 {
+			return nil, nil, nil, fmt.Errorf("missing %s command", g.name)
+		}
+		for _, cmd := range g.commands {
+			if cmd.name == args[1] {
+				return g, cmd, args[2:], nil
+			}
+		}
+		return nil, nil, nil, fmt.Errorf("unknown %s command %q", g.name, args[1])
+	}
+	return nil, nil, nil, fmt.Errorf("unknown resource %q", args[0])
+}
+
+func (a *app) usage(fs *flag.FlagSet) {
+	w := a.stderr
+	fmt.Fprintln(w, "Usage: vocode [flags] <resource> <command> [flags] [args]")
+	fmt.Fprintln(w, "\nFlags:")
+	fs.PrintDefaults()
+	fmt.Fprintln(w, "\nResources:")
+	for _, g := range groups() {
+		fmt.Fprintf(w, "  %-26s %s\n", g.name, g.short)
+		for _, cmd := range g.commands {
+			fmt.Fprintf(w, "    %-24s %s\n", strings.TrimSpace(cmd.name+" "+cmd.args), cmd.short)
+		}
+	}
+	fmt.Fprintln(w, "\nRun 'vocode <resource> <command> -h' for the command flags.")
+}
+
+// flagSet returns the new flag set of the command.
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	return fs
+}
+
+// parseID parses the args of the command
+// which takes the single resource ID.
+func (a *app) parseID(name string, args []string) (string, error) {
+	id, args := splitID(args)
+	fs := a.flagSet(name)
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	rest := fs.Args()
+	if id == "" && len(rest) > 0 {
+		id, rest = rest[0], rest[1:]
+	}
+	if id == "" {
+		return "", fmt.Errorf("%s: missing argument", name)
+	}
+	if len(rest) > 0 {
+		return "", fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	return id, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/vocodetest"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	s := vocodetest.NewServer()
+	t.Cleanup(s.Close)
+
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "config.json")
+	data, err := json.Marshal(config{
+		Profile: "test",
+		Profiles: map[string]profile{
+			"test": {APIKey: vocodetest.DefaultAPIKey, BaseURL: s.URL},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfg, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	getenv := func(string) string { return "" }
+
+	cli := func(stdin string, args ...string) string {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		a := &app{
+			stdin:  strings.NewReader(stdin),
+			stdout: &stdout,
+			stderr: &stderr,
+		}
+		if err := run(context.Background(), a, append([]string{"-config", cfg}, args...), getenv); err != nil {
+			t.Fatalf("vocode %s: %v\n%s", strings.Join(args, " "), err, stderr.String())
+		}
+		return stdout.String()
+	}
+	id := func(out string) string {
+		t.Helper()
+		var v struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(out), &v); err != nil {
+			t.Fatalf("decode %s: %v", out, err)
+		}
+		return v.ID
+	}
+
+	promptID := id(cli("", "-output", "json", "prompts", "create", "-content", "You are a support agent."))
+	voiceYAML := "type: voice_rime\nspeaker: young_male\nspeed_alpha: 1.2\n"
+	voiceID := id(cli(voiceYAML, "-output", "json", "voices", "create", "-f", "-"))
+	voiceJSON := `{"type": "voice_rime", "speaker": "young_female"}`
+	if id(cli(voiceJSON, "-output", "json", "voices", "create", "-f", "-")) == "" {
+		t.Fatal("expected voice ID")
+	}
+
+	agentFile := filepath.Join(dir, "agent.yaml")
+	agentYAML := "name: Support\nlanguage: en\nprompt: " + promptID + "\nvoice: " + voiceID + "\n"
+	if err := os.WriteFile(agentFile, []byte(agentYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	agentID := id(cli("", "-output", "json", "agents", "create", "-f", agentFile, "-name", "Sales"))
+
+	// the flags override the file and the update keeps the other fields
+	cli("", "agents", "update", agentID, "-language", "fr")
+	var agent vocode.Agent
+	if err := json.Unmarshal([]byte(cli("", "-output", "json", "agents", "get", agentID)), &agent); err != nil {
+		t.Fatal(err)
+	}
+	if agent.Name != "Sales" || agent.Language != "fr" || agent.Prompt == nil || agent.Prompt.ID != promptID {
+		t.Fatalf("unexpected agent: %+v", agent)
+	}
+
+	out := cli("", "agents", "list")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[1], "Sales") {
+		t.Fatalf("unexpected table:\n%s", out)
+	}
+
+	out = cli("", "-output", "yaml", "prompts", "get", promptID)
+	if !strings.Contains(out, "content: You are a support agent.\n") {
+		t.Fatalf("unexpected yaml:\n%s", out)
+	}
+
+	out = cli("", "-output", "json", "prompts", "get", promptID)
+	if !strings.Contains(out, `"content": "You are a support agent."`) {
+		t.Fatalf("unexpected prompt:\n%s", out)
+	}
+
+	out = cli("", "-output", "json", "usage")
+	if !strings.Contains(out, `"plan_type": "plan_developer"`) {
+		t.Fatalf("unexpected usage:\n%s", out)
+	}
+
+	cli("", "agents", "delete", agentID)
+	if out := cli("", "-output", "json", "agents", "list"); strings.TrimSpace(out) != "[]" {
+		t.Fatalf("expected no agents, got:\n%s", out)
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		for _, args := range [][]string{
+			{"unknown", "list"},
+			{"agents", "unknown"},
+			{"agents", "get"},
+			{"agents", "get", "missing"},
+			{"-output", "xml", "agents", "list"},
+			{"-profile", "missing", "agents", "list"},
+		} {
+			a := &app{stdin: strings.NewReader(""), stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}}
+			if err := run(context.Background(), a, append([]string{"-config", cfg}, args...), getenv); err == nil {
+				t.Fatalf("vocode %s: expected error", strings.Join(args, " "))
+			}
+		}
+	})
+	t.Run("config", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		corrupt := filepath.Join(dir, "corrupt.json")
+		if err := os.WriteFile(corrupt, []byte("{"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		for path, exp := range map[string]string{
+			filepath.Join(dir, "missing.json"): `profile "test": no config file`,
+			corrupt:                            "config " + corrupt,
+		} {
+			a := &app{stdin: strings.NewReader(""), stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}}
+			err := run(context.Background(), a, []string{"-config", path, "-profile", "test", "agents", "list"}, getenv)
+			if err == nil || !strings.Contains(err.Error(), exp) {
+				t.Fatalf("expected error: %s, got: %v", exp, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+var numberCols = []column[vocode.Number]{
+	{"NUMBER", func(n *vocode.Number) string { return n.Number }},
+	{"LABEL", func(n *vocode.Number) string { return n.Label }},
+	{"ACTIVE", func(n *vocode.Number) string { return fmt.Sprint(n.Active) }},
+	{"PROVIDER", func(n *vocode.Number) string { return string(n.TelProvider) }},
+	{"INBOUND AGENT", func(n *vocode.Number) string {
+		if n.InboundAgent == nil {
+			return ""
+		}
+		return n.InboundAgent.ID
+	}},
+}
+
+func numbersGroup() *group {
+	return &group{
+		name:  "numbers",
+		short: "manage phone numbers",
+		commands: []*command{
+			{name: "list", short: "list phone numbers", run: func(ctx context.Context, a *app, args []string) error {
+				return listCmd(ctx, a, "numbers list", args, (*vocode.Client).AllNumbers, numberCols)
+			}},
+			{name: "get", args: "<number>", short: "get the phone number", run: getNumber},
+			{name: "buy", args: "[-f file]", short: "buy a phone number", run: buyNumber},
+			{name: "update", args: "<number> [-f file]", short: "update the phone number", run: updateNumber},
+			{name: "cancel", args: "<number>", short: "cancel the phone number", run: cancelNumber},
+		},
+	}
+}
+
+func getNumber(ctx context.Context, a *app, args []string) error {
+	nr, err := a.parseID("numbers get", args)
+	if err != nil {
+		return err
+	}
+	n, err := a.client.GetNumber(ctx, nr)
+	if err != nil {
+		return err
+	}
+	return writeItem(a, n, numberCols)
+}
+
+func buyNumber(ctx context.Context, a *app, args []string) error {
+	req := &vocode.BuyNumberReq{}
+	rest, err := parseInput(a, "numbers buy", args, req, func(fs *flag.FlagSet, r *vocode.BuyNumberReq) {
+		fs.StringVar(&r.AreaCode, "area-code", r.AreaCode, "area `code` of the number")
+		fs.Var(stringFlag[vocode.TelProvider]{&r.TelProvider}, "provider", "telephony `provider`: twilio or vonage")
+		fs.StringVar(&r.TelAccountID, "account-connection", r.TelAccountID, "telephony account connection `ID`")
+	})
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	n, err := a.client.BuyNumber(ctx, req)
+	if err != nil {
+		return err
+	}
+	return writeItem(a, n, numberCols)
+}
+
+func updateNumber(ctx context.Context, a *app, args []string) error {
+	nr, args := splitID(args)
+	if nr == "" {
+		return fmt.Errorf("numbers update: missing argument")
+	}
+
+	// NOTE: the live number is fetched first so
+	// only the fields set by the input are updated.
+	n, err := a.client.GetNumber(ctx, nr)
+	if err != nil {
+		return err
+	}
+	req := &vocode.UpdateNumberReq{
+		Label:        n.Label,
+		OutboundOnly: n.OutboundOnly,
+		InboundAgent: n.InboundAgent,
+		ExampleCtx:   n.ExampleCtx,
+	}
+	rest, err := parseInput(a, "numbers update", args, req, func(fs *flag.FlagSet, r *vocode.UpdateNumberReq) {
+		fs.StringVar(&r.Label, "label", r.Label, "number `label`")
+		fs.BoolVar(&r.OutboundOnly, "outbound-only", r.OutboundOnly, "use the number for outbound calls only")
+		fs.Var(funcFlag{
+			set: func(id string) {
+				r.InboundAgent = nil
+				if id != "" {
+					r.InboundAgent = &vocode.Agent{ID: id}
+				}
+			},
+			get: func() string {
+				if r.InboundAgent == nil {
+					return ""
+				}
+				return r.InboundAgent.ID
+			},
+		}, "inbound-agent", "inbound agent `ID`")
+	})
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	n, err = a.client.UpdateNumber(ctx, nr, req)
+	if err != nil {
+		return err
+	}
+	return writeItem(a, n, numberCols)
+}
+
+func cancelNumber(ctx context.Context, a *app, args []string) error {
+	nr, err := a.parseID("numbers cancel", args)
+	if err != nil {
+		return err
+	}
+	n, err := a.client.CancelNumber(ctx, nr)
+	if err != nil {
+		return err
+	}
+	return writeItem(a, n, numberCols)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Format is the output format.
+type Format string
+
+const (
+	TableFormat Format = "table"
+	JSONFormat  Format = "json"
+	YAMLFormat  Format = "yaml"
+)
+
+// Set implements flag.Value.
+func (f *Format) Set(s string) error {
+	switch Format(s) {
+	case TableFormat, JSONFormat, YAMLFormat:
+		*f = Format(s)
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %q", s)
+}
+
+// String implements flag.Value.
+func (f *Format) String() string {
+	return string(*f)
+}
+
+// column is the table column of the resource T.
+type column[T any] struct {
+	header string
+	value  func(*T) string
+}
+
+// write writes v in the format. The table
+// rows are the values of the cols of items.
+func write[T any](w io.Writer, format Format, v any, items []T, cols []column[T]) error {
+	switch format {
+	case JSONFormat:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case YAMLFormat:
+		// NOTE: the value is encoded via its JSON tags and marshalers
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(cols))
+	for i, c := range cols {
+		headers[i] = c.header
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for i := range items {
+		row := make([]string, len(cols))
+		for j, c := range cols {
+			row[j] = cell(c.value(&items[i]))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// cell returns s as the single line table cell.
+func cell(s string) string {
+	const maxLen = 48
+
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > maxLen {
+		s = string(r[:maxLen-3]) + "..."
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// resource provides the list, get, create, update and delete
+// commands of the API resource T created from the request R.
+type resource[T, R any] struct {
+	name  string
+	short string
+	cols  []column[T]
+
+	all    func(*vocode.Client, context.Context, *vocode.ListOptions) *vocode.Pager[T]
+	get    func(*vocode.Client, context.Context, string) (*T, error)
+	create func(context.Context, *vocode.Client, *R) (*T, error)
+	update func(context.Context, *vocode.Client, string, *R) (*T, error)
+	delete func(*vocode.Client, context.Context, string) error
+	// req returns the request which recreates the live resource.
+	// The update request fields are set to the live values by it.
+	req func(*T) R
+	// bind binds the request fields to the command flags.
+	bind func(*flag.FlagSet, *R)
+}
+
+func (r *resource[T, R]) group() *group {
+	kind := strings.TrimSuffix(r.name, "s")
+	return &group{
+		name:  r.name,
+		short: r.short,
+		commands: []*command{
+			{name: "list", short: "list " + r.name, run: r.list},
+			{name: "get", args: "<id>", short: "get the " + kind, run: r.getCmd},
+			{name: "create", args: "[-f file]", short: "create the " + kind, run: r.createCmd},
+			{name: "update", args: "<id> [-f file]", short: "update the " + kind, run: r.updateCmd},
+			{name: "delete", args: "<id>", short: "delete the " + kind, run: r.deleteCmd},
+		},
+	}
+}
+
+func (r *resource[T, R]) list(ctx context.Context, a *app, args []string) error {
+	return listCmd(ctx, a, r.name+" list", args, r.all, r.cols)
+}
+
+func (r *resource[T, R]) getCmd(ctx context.Context, a *app, args []string) error {
+	id, err := a.parseID(r.name+" get", args)
+	if err != nil {
+		return err
+	}
+	item, err := r.get(a.client, ctx, id)
+	if err != nil {
+		return err
+	}
+	return writeItem(a, item, r.cols)
+}
+
+func (r *resource[T, R]) createCmd(ctx context.Context, a *app, args []string) error {
+	req := new(R)
+	rest, err := parseInput(a, r.name+" create", args, req, r.bind)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	item, err := r.create(ctx, a.client, req)
+	if err != nil {
+		return err
+	}
+	return writeItem(a, item, r.cols)
+}
+
+func (r *resource[T, R]) updateCmd(ctx context.Context, a *app, args []string) error {
+	id, args := splitID(args)
+	if id == "" {
+		return fmt.Errorf("%s update: missing argument", r.name)
+	}
+
+	// NOTE: the live resource is fetched first so
+	// only the fields set by the input are updated.
+	live, err := r.get(a.client, ctx, id)
+	if err != nil {
+		return err
+	}
+	req := r.req(live)
+	rest, err := parseInput(a, r.name+" update", args, &req, r.bind)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	item, err := r.update(ctx, a.client, id, &req)
+	if err != nil {
+		return err
+	}
+	return writeItem(a, item, r.cols)
+}
+
+func (r *resource[T, R]) deleteCmd(ctx context.Context, a *app, args []string) error {
+	id, err := a.parseID(r.name+" delete", args)
+	if err != nil {
+		return err
+	}
+	return r.delete(a.client, ctx, id)
+}
+
+// listCmd writes all the items of the pager returned by all.
+func listCmd[T any](ctx context.Context, a *app, name string, args []string,
+	all func(*vocode.Client, context.Context, *vocode.ListOptions) *vocode.Pager[T], cols []column[T]) error {
+	fs := a.flagSet(name)
+	opts := &vocode.ListOptions{}
+	fs.IntVar(&opts.Limit, "limit", 0, "list at most `n` items")
+	fs.IntVar(&opts.Size, "page-size", 0, "fetch `n` items per page")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	items := []T{}
+	p := all(a.client, ctx, opts)
+	for p.Next() {
+		items = append(items, p.Item())
+	}
+	if err := p.Err(); err != nil {
+		return err
+	}
+	return write(a.stdout, a.format, items, items, cols)
+}
+
+// writeItem writes the single item.
+func writeItem[T any](a *app, item *T, cols []column[T]) error {
+	return write(a.stdout, a.format, item, []T{*item}, cols)
+}
+
+// splitID splits the leading positional ID off the args.
+func splitID(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args[0], args[1:]
+	}
+	return "", args
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strings"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/spec"
+)
+
+var agents = &resource[vocode.Agent, vocode.AgentReq]{
+	name:  "agents",
+	short: "manage agents",
+	cols: []column[vocode.Agent]{
+		{"ID", func(a *vocode.Agent) string { return a.ID }},
+		{"NAME", func(a *vocode.Agent) string { return a.Name }},
+		{"LANGUAGE", func(a *vocode.Agent) string { return string(a.Language) }},
+		{"PROMPT", func(a *vocode.Agent) string {
+			if a.Prompt == nil {
+				return ""
+			}
+			return a.Prompt.ID
+		}},
+		{"VOICE", func(a *vocode.Agent) string {
+			if a.Voice == nil {
+				return ""
+			}
+			return a.Voice.ID
+		}},
+		{"ACTIONS", func(a *vocode.Agent) string { return itemIDs(a.Actions, func(a *vocode.Action) string { return a.ID }) }},
+	},
+	all: (*vocode.Client).AllAgents,
+	get: (*vocode.Client).GetAgent,
+	create: func(ctx context.Context, c *vocode.Client, r *vocode.AgentReq) (*vocode.Agent, error) {
+		return c.CreateAgent(ctx, &vocode.CreateAgentReq{AgentReq: *r})
+	},
+	update: func(ctx context.Context, c *vocode.Client, id string, r *vocode.AgentReq) (*vocode.Agent, error) {
+		return c.UpdateAgent(ctx, id, &vocode.UpdateAgentReq{AgentReq: *r})
+	},
+	delete: (*vocode.Client).DeleteAgent,
+	req:    spec.AgentReq,
+	bind: func(fs *flag.FlagSet, r *vocode.AgentReq) {
+		fs.StringVar(&r.Name, "name", r.Name, "agent `name`")
+		fs.StringVar(&r.Prompt, "prompt", r.Prompt, "prompt `ID`")
+		fs.StringVar(&r.Voice, "voice", r.Voice, "voice `ID`")
+		fs.Var(stringFlag[vocode.Language]{&r.Language}, "language", "agent `language`")
+		fs.Var(listFlag[string]{&r.Actions}, "actions", "comma separated action `IDs`")
+		fs.StringVar(&r.Webhook, "webhook", r.Webhook, "webhook `ID`")
+		fs.StringVar(&r.VectorDB, "vector-db", r.VectorDB, "vector database `ID`")
+		fs.StringVar(&r.InitMsg, "initial-message", r.InitMsg, "initial `message`")
+	},
+}
+
+var prompts = &resource[vocode.Prompt, vocode.PromptReq]{
+	name:  "prompts",
+	short: "manage prompts",
+	cols: []column[vocode.Prompt]{
+		{"ID", func(p *vocode.Prompt) string { return p.ID }},
+		{"CONTENT", func(p *vocode.Prompt) string { return p.Content }},
+	},
+	all: (*vocode.Client).AllPrompts,
+	get: (*vocode.Client).GetPrompt,
+	create: func(ctx context.Context, c *vocode.Client, r *vocode.PromptReq) (*vocode.Prompt, error) {
+		return c.CreatePrompt(ctx, &vocode.CreatePromptReq{PromptReq: *r})
+	},
+	update: func(ctx context.Context, c *vocode.Client, id string, r *vocode.PromptReq) (*vocode.Prompt, error) {
+		return c.UpdatePrompt(ctx, id, &vocode.UpdatePromptReq{PromptReq: *r})
+	},
+	delete: (*vocode.Client).DeletePrompt,
+	req:    spec.PromptReq,
+	bind: func(fs *flag.FlagSet, r *vocode.PromptReq) {
+		fs.StringVar(&r.Content, "content", r.Content, "prompt `content`")
+		fs.StringVar(&r.CtxEndpoint, "context-endpoint", r.CtxEndpoint, "context endpoint `URL`")
+		fs.StringVar(&r.Template, "template", r.Template, "prompt template `ID`")
+	},
+}
+
+// NOTE: the voice, action and account connection configs
+// depend on their type so they're read from the file.
+
+var voices = &resource[vocode.Voice, vocode.VoiceReq]{
+	name:  "voices",
+	short: "manage voices",
+	cols: []column[vocode.Voice]{
+		{"ID", func(v *vocode.Voice) string { return v.ID }},
+		{"TYPE", func(v *vocode.Voice) string { return string(v.Type) }},
+	},
+	all: (*vocode.Client).AllVoices,
+	get: (*vocode.Client).GetVoice,
+	create: func(ctx context.Context, c *vocode.Client, r *vocode.VoiceReq) (*vocode.Voice, error) {
+		return c.CreateVoice(ctx, &vocode.CreateVoiceReq{VoiceReq: *r})
+	},
+	update: func(ctx context.Context, c *vocode.Client, id string, r *vocode.VoiceReq) (*vocode.Voice, error) {
+		return c.UpdateVoice(ctx, id, &vocode.UpdateVoiceReq{VoiceReq: *r})
+	},
+	delete: (*vocode.Client).DeleteVoice,
+	req:    spec.VoiceReq,
+}
+
+var actions = &resource[vocode.Action, vocode.ActionReq]{
+	name:  "actions",
+	short: "manage actions",
+	cols: []column[vocode.Action]{
+		{"ID", func(a *vocode.Action) string { return a.ID }},
+		{"TYPE", func(a *vocode.Action) string { return string(a.Type) }},
+		{"TRIGGER", func(a *vocode.Action) string {
+			if a.Trigger == nil {
+				return ""
+			}
+			return string(a.Trigger.TriggerType())
+		}},
+	},
+	all: (*vocode.Client).AllActions,
+	get: (*vocode.Client).GetAction,
+	create: func(ctx context.Context, c *vocode.Client, r *vocode.ActionReq) (*vocode.Action, error) {
+		return c.CreateAction(ctx, &vocode.CreateActionReq{ActionReq: *r})
+	},
+	update: func(ctx context.Context, c *vocode.Client, id string, r *vocode.ActionReq) (*vocode.Action, error) {
+		return c.UpdateAction(ctx, id, &vocode.UpdateActionReq{ActionReq: *r})
+	},
+	delete: (*vocode.Client).DeleteAction,
+	req:    spec.ActionReq,
+}
+
+var webhooks = &resource[vocode.Webhook, vocode.WebhookReq]{
+	name:  "webhooks",
+	short: "manage webhooks",
+	cols: []column[vocode.Webhook]{
+		{"ID", func(w *vocode.Webhook) string { return w.ID }},
+		{"METHOD", func(w *vocode.Webhook) string { return string(w.Method) }},
+		{"URL", func(w *vocode.Webhook) string { return w.URL }},
+		{"SUBSCRIPTIONS", func(w *vocode.Webhook) string { return listFlag[vocode.Event]{&w.Subs}.String() }},
+	},
+	all: (*vocode.Client).AllWebhooks,
+	get: (*vocode.Client).GetWebhook,
+	create: func(ctx context.Context, c *vocode.Client, r *vocode.WebhookReq) (*vocode.Webhook, error) {
+		return c.CreateWebhook(ctx, &vocode.CreateWebhookReq{WebhookReq: *r})
+	},
+	update: func(ctx context.Context, c *vocode.Client, id string, r *vocode.WebhookReq) (*vocode.Webhook, error) {
+		return c.UpdateWebhook(ctx, id, &vocode.UpdateWebhookReq{WebhookReq: *r})
+	},
+	delete: (*vocode.Client).DeleteWebhook,
+	req:    spec.WebhookReq,
+	bind: func(fs *flag.FlagSet, r *vocode.WebhookReq) {
+		fs.StringVar(&r.URL, "url", r.URL, "webhook `URL`")
+		fs.Var(stringFlag[vocode.WebhookMethod]{&r.Method}, "method", "HTTP `method`")
+		fs.Var(listFlag[vocode.Event]{&r.Subs}, "subscriptions", "comma separated `events`")
+	},
+}
+
+var vectorDBs = &resource[vocode.VectorDB, vocode.VectorDBReq]{
+	name:  "vector-dbs",
+	short: "manage vector databases",
+	cols: []column[vocode.VectorDB]{
+		{"ID", func(v *vocode.VectorDB) string { return v.ID }},
+		{"TYPE", func(v *vocode.VectorDB) string { return string(v.Type) }},
+		{"INDEX", func(v *vocode.VectorDB) string { return v.Index }},
+		{"ENVIRONMENT", func(v *vocode.VectorDB) string { return v.APIEnv }},
+	},
+	all: (*vocode.Client).AllVectorDBs,
+	get: (*vocode.Client).GetVectorDB,
+	create: func(ctx context.Context, c *vocode.Client, r *vocode.VectorDBReq) (*vocode.VectorDB, error) {
+		return c.CreateVectorDB(ctx, &vocode.CreateVectorDBReq{VectorDBReq: *r})
+	},
+	update: func(ctx context.Context, c *vocode.Client, id string, r *vocode.VectorDBReq) (*vocode.VectorDB, error) {
+		return c.UpdateVectorDB(ctx, id, &vocode.UpdateVectorDBReq{VectorDBReq: *r})
+	},
+	delete: (*vocode.Client).DeleteVectorDB,
+	req:    spec.VectorDBReq,
+	bind: func(fs *flag.FlagSet, r *vocode.VectorDBReq) {
+		fs.Var(stringFlag[vocode.VectorDBType]{&r.Type}, "type", "vector database `type`")
+		fs.StringVar(&r.Index, "index", r.Index, "`index` name")
+		fs.StringVar(&r.APIKey, "api-key", r.APIKey, "vector database API `key`")
+		fs.StringVar(&r.APIEnv, "api-env", r.APIEnv, "vector database API `environment`")
+	},
+}
+
+var accountConns = &resource[vocode.AccountConn, vocode.AccountConnReq]{
+	name:  "account-connections",
+	short: "manage account connections",
+	cols: []column[vocode.AccountConn]{
+		{"ID", func(a *vocode.AccountConn) string { return a.ID }},
+		{"TYPE", func(a *vocode.AccountConn) string { return string(a.Type) }},
+	},
+	all: (*vocode.Client).AllAccountConns,
+	get: (*vocode.Client).GetAccountConn,
+	create: func(ctx context.Context, c *vocode.Client, r *vocode.AccountConnReq) (*vocode.AccountConn, error) {
+		return c.CreateAccountConn(ctx, &vocode.CreateAccountConnReq{AccountConnReq: *r})
+	},
+	update: func(ctx context.Context, c *vocode.Client, id string, r *vocode.AccountConnReq) (*vocode.AccountConn, error) {
+		return c.UpdateAccountConn(ctx, id, &vocode.UpdateAccountConnReq{AccountConnReq: *r})
+	},
+	delete: (*vocode.Client).DeleteAccountConn,
+	req:    spec.AccountConnReq,
+}
+
+// itemIDs returns the comma separated IDs of items.
+func itemIDs[T any](items []T, id func(*T) string) string {
+	ids := make([]string, len(items))
+	for i := range items {
+		ids[i] = id(&items[i])
+	}
+	return strings.Join(ids, ",")
+}
@@ -0,0 +1,189 @@
+package vocode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/milosgajdos/go-vocode/credentials"
+)
+
+// Config is the client config file which configures the named profiles.
+//
+//	{
+//	  "profile": "dev",
+//	  "profiles": {
+//	    "dev": {"api_key_env": "VOCODE_DEV_API_KEY"},
+//	    "staging": {"api_key_file": "~/.vocode/staging.key"},
+//	    "prod": {"api_key_command": ["op", "read", "op://prod/vocode/api-key"], "api_key_ttl": "15m"}
+//	  }
+//	}
+type Config struct {
+	// Profile is the name of the default profile.
+	Profile  string             `json:"profile,omitempty"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// Profile configures the client. The API key is read from exactly
+// one of the APIKey, APIKeyEnv, APIKeyFile or APIKeyCommand.
+type Profile struct {
+	APIKey     string `json:"api_key,omitempty"`
+	APIKeyEnv  string `json:"api_key_env,omitempty"`
+	APIKeyFile string `json:"api_key_file,omitempty"`
+	// APIKeyCommand is the command which writes the key to stdout.
+	APIKeyCommand []string `json:"api_key_command,omitempty"`
+	// APIKeyTTL is how long the key read by the command is cached.
+	// It's parsed by time.ParseDuration; the key is cached until
+	// the API rejects it if it's not set.
+	APIKeyTTL string `json:"api_key_ttl,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+	BaseURL   string `json:"base_url,omitempty"`
+	Version   string `json:"version,omitempty"`
+}
+
+// DefaultConfigPath returns the path of the config file set by
+// VOCODE_CONFIG env var. By default it's vocode/config.json
+// in the user config directory.
+func DefaultConfigPath() (string, error) {
+	if path := os.Getenv("VOCODE_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vocode", "config.json"), nil
+}
+
+// LoadConfig loads the Config from the JSON file at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Config)
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Options returns the client options of the named profile.
+// The default profile is used if the name is empty.
+func (c *Config) Options(name string) ([]Option, error) {
+	if name == "" {
+		name = c.Profile
+	}
+	if name == "" {
+		return nil, errors.New("no profile")
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found", name)
+	}
+	creds, err := p.Credentials()
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", name, err)
+	}
+
+	opts := []Option{WithCredentials(creds)}
+	if p.UserID != "" {
+		opts = append(opts, WithUserID(p.UserID))
+	}
+	if p.BaseURL != "" {
+		opts = append(opts, WithBaseURL(p.BaseURL))
+	}
+	if p.Version != "" {
+		opts = append(opts, WithVersion(p.Version))
+	}
+	return opts, nil
+}
+
+// Credentials returns the provider of the profile API key.
+func (p Profile) Credentials() (credentials.Provider, error) {
+	var (
+		creds credentials.Provider
+		n     int
+	)
+	if p.APIKey != "" {
+		creds, n = credentials.Static(p.APIKey), n+1
+	}
+	if p.APIKeyEnv != "" {
+		creds, n = credentials.Env(p.APIKeyEnv), n+1
+	}
+	if p.APIKeyFile != "" {
+		creds, n = credentials.NewFile(expandHome(p.APIKeyFile)), n+1
+	}
+	if len(p.APIKeyCommand) > 0 {
+		var ttl time.Duration
+		if p.APIKeyTTL != "" {
+			d, err := time.ParseDuration(p.APIKeyTTL)
+			if err != nil {
+				return nil, fmt.Errorf("api_key_ttl: %w", err)
+			}
+			ttl = d
+		}
+		creds, n = credentials.NewCommand(ttl, p.APIKeyCommand[0], p.APIKeyCommand[1:]...), n+1
+	}
+
+	switch n {
+	case 0:
+		return nil, errors.New("no API key")
+	case 1:
+		return creds, nil
+	}
+	return nil, errors.New("more than one API key source")
+}
+
+// WithProfile configures the client with the named profile from
+// the config file at DefaultConfigPath. The default profile is used
+// if the name is empty. If the profile can't be loaded, all the client
+// requests fail with the error.
+func WithProfile(name string) Option {
+	return func(o *Options) {
+		opts, err := profileOptions(name)
+		if err != nil {
+			o.Credentials = failedCredentials{err: err}
+			return
+		}
+		for _, apply := range opts {
+			apply(o)
+		}
+	}
+}
+
+func profileOptions(name string) ([]Option, error) {
+	path, err := DefaultConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	c, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.Options(name)
+}
+
+// failedCredentials return the error of the failed config.
+type failedCredentials struct {
+	err error
+}
+
+func (f failedCredentials) APIKey(context.Context) (string, error) {
+	return "", f.err
+}
+
+func expandHome(path string) string {
+	if len(path) < 2 || path[:2] != "~/" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
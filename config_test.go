@@ -0,0 +1,132 @@
+package vocode_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/vocodetest"
+)
+
+// rotating provides the stale key until it's refreshed.
+type rotating struct {
+	mu        sync.Mutex
+	key       string
+	next      string
+	refreshes int
+}
+
+func (r *rotating) APIKey(context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.key, nil
+}
+
+func (r *rotating) Refresh() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.key = r.next
+	r.refreshes++
+}
+
+func TestCredentialsRefresh(t *testing.T) {
+	t.Parallel()
+
+	s := vocodetest.NewServer()
+	t.Cleanup(s.Close)
+
+	creds := &rotating{key: "stale", next: vocodetest.DefaultAPIKey}
+	c := vocode.NewClient(vocode.WithBaseURL(s.URL), vocode.WithCredentials(creds))
+	ctx := context.Background()
+
+	if _, err := c.CreatePrompt(ctx, &vocode.CreatePromptReq{PromptReq: vocode.PromptReq{Content: "prompt"}}); err != nil {
+		t.Fatalf("expected the request to be retried with the new key, got: %v", err)
+	}
+	if _, err := c.GetUsage(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if creds.refreshes != 1 {
+		t.Fatalf("expected 1 refresh, got: %d", creds.refreshes)
+	}
+
+	// the requests without body are retried, too
+	creds = &rotating{key: "stale", next: vocodetest.DefaultAPIKey}
+	c = vocode.NewClient(vocode.WithBaseURL(s.URL), vocode.WithCredentials(creds))
+	userID, err := c.UserID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID != vocodetest.DefaultUserID {
+		t.Fatalf("expected user ID: %s, got: %s", vocodetest.DefaultUserID, userID)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	s := vocodetest.NewServer()
+	t.Cleanup(s.Close)
+
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "prod.key")
+	if err := os.WriteFile(keyPath, []byte(vocodetest.DefaultAPIKey+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(dir, "config.json")
+	cfgJSON := `{
+		"profile": "dev",
+		"profiles": {
+			"dev": {"api_key": "dev-key", "base_url": "` + s.URL + `"},
+			"prod": {"api_key_file": "` + keyPath + `", "base_url": "` + s.URL + `", "user_id": "prod-user"},
+			"broken": {"api_key": "key", "api_key_env": "KEY"}
+		}
+	}`
+	if err := os.WriteFile(cfgPath, []byte(cfgJSON), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := vocode.LoadConfig(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+		opts, err := cfg.Options("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := vocode.NewClient(opts...).GetUsage(ctx); !errors.Is(err, vocode.ErrUnauthorized) {
+			t.Fatalf("expected unauthorized error, got: %v", err)
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		t.Parallel()
+		opts, err := cfg.Options("prod")
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := vocode.NewClient(opts...)
+		if _, err := c.GetUsage(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if id, _ := c.UserID(ctx); id != "prod-user" {
+			t.Fatalf("expected user ID: prod-user, got: %s", id)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		for _, name := range []string{"missing", "broken"} {
+			if _, err := cfg.Options(name); err == nil {
+				t.Fatalf("expected profile %q error", name)
+			}
+		}
+	})
+}
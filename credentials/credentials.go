@@ -0,0 +1,202 @@
+// Package credentials provides the Vocode API keys.
+//
+// The Provider returns the API key which is used to authorize every API
+// request so the keys can be rotated without rebuilding the client: the
+// File provider reloads the key when the file changes and the Command
+// provider runs its command again when the cached key expires. The client
+// refreshes the Provider which implements Refresher when the API rejects
+// the key and retries the request with the new key.
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoCredentials is returned by the Provider which has no API key.
+	ErrNoCredentials = errors.New("no credentials")
+)
+
+// Provider provides the API key.
+type Provider interface {
+	// APIKey returns the API key.
+	APIKey(ctx context.Context) (string, error)
+}
+
+// Refresher is implemented by the Providers which cache the API key.
+type Refresher interface {
+	// Refresh discards the cached API key
+	// so that the next APIKey call reloads it.
+	Refresh()
+}
+
+// Static is the Provider of the static API key.
+type Static string
+
+// APIKey implements Provider.
+func (s Static) APIKey(context.Context) (string, error) {
+	if s == "" {
+		return "", ErrNoCredentials
+	}
+	return string(s), nil
+}
+
+// Env is the Provider which reads the API key
+// from the environment variable on every call.
+type Env string
+
+// APIKey implements Provider.
+func (e Env) APIKey(context.Context) (string, error) {
+	key := os.Getenv(string(e))
+	if key == "" {
+		return "", fmt.Errorf("env %s: %w", string(e), ErrNoCredentials)
+	}
+	return key, nil
+}
+
+// File is the Provider which reads the API key from the file.
+// The key is reloaded when the file is modified.
+type File struct {
+	path string
+
+	mu      sync.Mutex
+	key     string
+	modTime time.Time
+	size    int64
+}
+
+// NewFile creates a new File provider which reads the key from path.
+// The leading and trailing white space is trimmed from the key.
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// APIKey implements Provider.
+func (f *File) APIKey(context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return "", err
+	}
+	if f.key != "" && fi.ModTime().Equal(f.modTime) && fi.Size() == f.size {
+		return f.key, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", err
+	}
+	key := string(bytes.TrimSpace(data))
+	if key == "" {
+		return "", fmt.Errorf("file %s: %w", f.path, ErrNoCredentials)
+	}
+	f.key, f.modTime, f.size = key, fi.ModTime(), fi.Size()
+	return f.key, nil
+}
+
+// Refresh implements Refresher.
+func (f *File) Refresh() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.key = ""
+}
+
+// Command is the Provider which reads the API key from the output
+// of the command, such as the secrets manager CLI. The key is cached
+// for the TTL; it is cached until it's refreshed if the TTL is zero.
+type Command struct {
+	name string
+	args []string
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	key     string
+	expires time.Time
+}
+
+// NewCommand creates a new Command provider which runs the named
+// program with args and caches the key it writes to stdout for ttl.
+func NewCommand(ttl time.Duration, name string, args ...string) *Command {
+	return &Command{
+		name: name,
+		args: args,
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+// APIKey implements Provider.
+func (c *Command) APIKey(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.key != "" && (c.ttl == 0 || c.now().Before(c.expires)) {
+		return c.key, nil
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.name, c.args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("command %s: %w: %s", c.name, err, msg)
+		}
+		return "", fmt.Errorf("command %s: %w", c.name, err)
+	}
+	key := string(bytes.TrimSpace(out))
+	if key == "" {
+		return "", fmt.Errorf("command %s: %w", c.name, ErrNoCredentials)
+	}
+	c.key, c.expires = key, c.now().Add(c.ttl)
+	return c.key, nil
+}
+
+// Refresh implements Refresher.
+func (c *Command) Refresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.key = ""
+}
+
+// Chain is the Provider which returns the API key
+// of the first of its Providers which has the key.
+type Chain []Provider
+
+// APIKey implements Provider.
+// It skips the Providers which return ErrNoCredentials.
+func (c Chain) APIKey(ctx context.Context) (string, error) {
+	for _, p := range c {
+		key, err := p.APIKey(ctx)
+		if err == nil {
+			return key, nil
+		}
+		if !errors.Is(err, ErrNoCredentials) {
+			return "", err
+		}
+	}
+	return "", ErrNoCredentials
+}
+
+// Refresh implements Refresher.
+// It refreshes all the Providers which implement Refresher.
+func (c Chain) Refresh() {
+	for _, p := range c {
+		if r, ok := p.(Refresher); ok {
+			r.Refresh()
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("key-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f := NewFile(path)
+	ctx := context.Background()
+
+	if key, err := f.APIKey(ctx); err != nil || key != "key-1" {
+		t.Fatalf("expected key: key-1, got: %q (%v)", key, err)
+	}
+
+	// the rotated key is reloaded
+	if err := os.WriteFile(path, []byte("rotated-key-2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if key, err := f.APIKey(ctx); err != nil || key != "rotated-key-2" {
+		t.Fatalf("expected key: rotated-key-2, got: %q (%v)", key, err)
+	}
+
+	if err := os.WriteFile(path, []byte("  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.APIKey(ctx); !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("expected error: %v, got: %v", ErrNoCredentials, err)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("key-1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	c := NewCommand(time.Minute, "cat", path)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if key, err := c.APIKey(ctx); err != nil || key != "key-1" {
+		t.Fatalf("expected key: key-1, got: %q (%v)", key, err)
+	}
+	if err := os.WriteFile(path, []byte("key-2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the key is cached until it expires or it's refreshed
+	if key, _ := c.APIKey(ctx); key != "key-1" {
+		t.Fatalf("expected cached key: key-1, got: %q", key)
+	}
+	now = now.Add(time.Minute)
+	if key, _ := c.APIKey(ctx); key != "key-2" {
+		t.Fatalf("expected expired key: key-2, got: %q", key)
+	}
+	if err := os.WriteFile(path, []byte("key-3"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c.Refresh()
+	if key, _ := c.APIKey(ctx); key != "key-3" {
+		t.Fatalf("expected refreshed key: key-3, got: %q", key)
+	}
+
+	if _, err := NewCommand(0, "false").APIKey(ctx); err == nil {
+		t.Fatal("expected command error")
+	}
+}
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := Chain{Static(""), Env("VOCODE_CREDENTIALS_TEST_UNSET"), Static("key")}
+	if key, err := c.APIKey(ctx); err != nil || key != "key" {
+		t.Fatalf("expected key: key, got: %q (%v)", key, err)
+	}
+	if _, err := (Chain{Static("")}).APIKey(ctx); !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("expected error: %v, got: %v", ErrNoCredentials, err)
+	}
+}
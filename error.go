@@ -1,27 +1,107 @@
 package vocode
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/milosgajdos/go-vocode/client"
+)
+
+var (
+	// ErrNotFound is matched by the API errors with 404 status code.
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized is matched by the API errors with 401 or 403 status code.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrRateLimited is matched by the API errors with 429 status code.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrValidation is matched by the API errors with 422 status code
+	// or by the API errors which contain the request param errors.
+	ErrValidation = errors.New("validation failed")
 )
 
+// APIError is returned when the API request fails.
+// Use errors.Is with the package sentinel errors
+// to check the type of the failure.
 type APIError struct {
 	ParamError     *APIParamError
 	GenError       *APIGenError
 	UnexpecedError json.RawMessage
+	// StatusCode is the HTTP response status code.
+	StatusCode int
+	// Method is the HTTP method of the failed request.
+	Method string
+	// URL is the URL of the failed request.
+	URL string
+	// Header contains the HTTP response headers.
+	Header http.Header
+	// Body is the raw HTTP response body.
+	Body []byte
 }
 
 func (e *APIError) Error() string {
-	if e.ParamError != nil {
-		return e.ParamError.Error()
+	msg := "unknown error"
+	switch {
+	case e.ParamError != nil:
+		msg = e.ParamError.Error()
+	case e.GenError != nil:
+		msg = e.GenError.Error()
+	case len(e.UnexpecedError) > 0:
+		msg = string(e.UnexpecedError)
+	case len(e.Body) > 0:
+		msg = string(e.Body)
 	}
-	if e.GenError != nil {
-		return e.GenError.Error()
+	if e.StatusCode == 0 {
+		return msg
 	}
-	if len(e.UnexpecedError) > 0 {
-		return string(e.UnexpecedError)
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode), msg)
+}
+
+// SetResponse records the details of the HTTP response the error was decoded from.
+func (e *APIError) SetResponse(resp *http.Response, body []byte) {
+	e.StatusCode = resp.StatusCode
+	e.Header = resp.Header
+	e.Body = body
+	if resp.Request != nil {
+		e.Method = resp.Request.Method
+		e.URL = resp.Request.URL.String()
 	}
-	return "unknown error"
+}
+
+// Is allows matching the error with the package sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrValidation:
+		return e.StatusCode == http.StatusUnprocessableEntity || e.ParamError != nil
+	}
+	return false
+}
+
+// IsRetryable returns true if the request which returned err
+// might succeed when retried: it failed with a network error
+// or with one of the client.DefaultRetryStatuses API errors.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return slices.Contains(client.DefaultRetryStatuses, apiErr.StatusCode)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
 }
 
 func (e *APIError) UnmarshalJSON(data []byte) error {
@@ -46,24 +126,200 @@ func (e *APIError) UnmarshalJSON(data []byte) error {
 
 // APIParamError is returned when API params are invalid.
 type APIParamError struct {
-	Detail []struct {
-		Type  string        `json:"type"`
-		Loc   []interface{} `json:"loc"`
-		Msg   string        `json:"msg"`
-		Input string        `json:"input,omitempty"`
-		Ctx   *struct {
-			Error string `json:"error"`
-		} `json:"ctx,omitempty"`
-	} `json:"detail"`
+	Detail []ParamErrorDetail `json:"detail"`
+}
+
+// ParamErrorDetail describes the single invalid API param.
+type ParamErrorDetail struct {
+	Type string `json:"type"`
+	// Loc is the location of the invalid param e.g.
+	// ["body", "actions", 0] or ["query", "id"].
+	Loc   []interface{} `json:"loc"`
+	Msg   string        `json:"msg"`
+	Input any           `json:"input,omitempty"`
+	Ctx   *struct {
+		Error string `json:"error"`
+	} `json:"ctx,omitempty"`
+}
+
+// Path returns the JSON path of the invalid param without its
+// location prefix e.g. actions[0].type for the ["body", "actions", 0, "type"].
+func (d ParamErrorDetail) Path() string {
+	loc := d.Loc
+	if len(loc) > 1 {
+		if s, ok := loc[0].(string); ok && (s == "body" || s == "query" || s == "path") {
+			loc = loc[1:]
+		}
+	}
+	var sb strings.Builder
+	for _, l := range loc {
+		switch v := l.(type) {
+		case string:
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(v)
+		case float64:
+			fmt.Fprintf(&sb, "[%d]", int(v))
+		case int:
+			fmt.Fprintf(&sb, "[%d]", v)
+		default:
+			fmt.Fprintf(&sb, "[%v]", v)
+		}
+	}
+	return sb.String()
 }
 
 // Error implements error interface.
 func (e APIParamError) Error() string {
-	b, err := json.Marshal(e)
-	if err != nil {
-		return "unknown error"
+	if len(e.Detail) == 0 {
+		return "invalid params"
+	}
+	var sb strings.Builder
+	sb.WriteString("invalid params:")
+	for _, d := range e.Detail {
+		fmt.Fprintf(&sb, "\n  %s: %s (%s)", d.Path(), d.Msg, d.Type)
+	}
+	return sb.String()
+}
+
+// FieldError describes the API request field rejected by the API.
+type FieldError struct {
+	// Path is the JSON path of the field.
+	Path string
+	// Field is the Go selector of the field in the request
+	// e.g. AgentReq.LLMTemperature. It's empty if the path
+	// could not be mapped to any request field.
+	Field string
+	// Msg is the validation error message.
+	Msg string
+	// Type is the validation error type.
+	Type string
+	// Input is the rejected input value.
+	Input any
+}
+
+// Fields maps all the invalid params to the fields of the request req
+// via their JSON struct tags. If req is nil only the paths are set.
+func (e APIParamError) Fields(req any) []FieldError {
+	fields := make([]FieldError, 0, len(e.Detail))
+	for _, d := range e.Detail {
+		fe := FieldError{
+			Path:  d.Path(),
+			Msg:   d.Msg,
+			Type:  d.Type,
+			Input: d.Input,
+		}
+		if len(d.Loc) > 0 && d.Loc[0] == "body" && req != nil {
+			fe.Field = fieldPath(reflect.ValueOf(req), d.Loc[1:])
+		}
+		fields = append(fields, fe)
+	}
+	return fields
+}
+
+// FieldErrors returns the field errors of the request req if err
+// is an API error caused by invalid params; otherwise it returns nil.
+func FieldErrors(err error, req any) []FieldError {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.ParamError == nil {
+		return nil
+	}
+	return apiErr.ParamError.Fields(req)
+}
+
+// fieldPath returns the Go selector of the field at loc in v.
+// If loc can't be fully resolved the longest resolved prefix is returned.
+func fieldPath(v reflect.Value, loc []any) string {
+	var sb strings.Builder
+	for _, l := range loc {
+		v = indirect(v)
+		switch key := l.(type) {
+		case string:
+			if v.Kind() != reflect.Struct {
+				return sb.String()
+			}
+			names, fv, ok := findField(v, key)
+			if !ok {
+				return sb.String()
+			}
+			for _, name := range names {
+				if sb.Len() > 0 {
+					sb.WriteByte('.')
+				}
+				sb.WriteString(name)
+			}
+			v = fv
+		case float64:
+			if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+				return sb.String()
+			}
+			i := int(key)
+			fmt.Fprintf(&sb, "[%d]", i)
+			if i < v.Len() {
+				v = v.Index(i)
+			} else {
+				v = reflect.Zero(v.Type().Elem())
+			}
+		default:
+			return sb.String()
+		}
+	}
+	return sb.String()
+}
+
+// findField finds the struct field with the JSON name in v. It looks into
+// the embedded structs and the struct fields which are not JSON encoded
+// directly but whose fields are flattened by the custom JSON marshalers.
+// It returns the names of the fields on the path to the found field.
+func findField(v reflect.Value, name string) ([]string, reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == name || (tag == "" && !f.Anonymous && strings.EqualFold(f.Name, name)) {
+			return []string{f.Name}, v.Field(i), true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || (!f.Anonymous && f.Tag.Get("json") != "-") {
+			continue
+		}
+		fv := indirect(v.Field(i))
+		if fv.Kind() != reflect.Struct {
+			continue
+		}
+		if names, found, ok := findField(fv, name); ok {
+			return append([]string{f.Name}, names...), found, true
+		}
+	}
+	return nil, reflect.Value{}, false
+}
+
+// indirect dereferences the pointers and interfaces in v.
+// Nil pointers are replaced with the zero value of their type.
+func indirect(v reflect.Value) reflect.Value {
+	for {
+		switch v.Kind() {
+		case reflect.Pointer:
+			if v.IsNil() {
+				v = reflect.Zero(v.Type().Elem())
+				continue
+			}
+			v = v.Elem()
+		case reflect.Interface:
+			if v.IsNil() {
+				return v
+			}
+			v = v.Elem()
+		default:
+			return v
+		}
 	}
-	return string(b)
 }
 
 // APIGenError is returned when a generic API error is returned.
@@ -0,0 +1,129 @@
+package vocode_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/client"
+	"github.com/milosgajdos/go-vocode/vocodetest"
+)
+
+func TestAPIError(t *testing.T) {
+	t.Parallel()
+
+	s := vocodetest.NewServer()
+	t.Cleanup(s.Close)
+	ctx := context.Background()
+
+	t.Run("not_found", func(t *testing.T) {
+		t.Parallel()
+		_, err := s.Client().GetAgent(ctx, "missing")
+		if !errors.Is(err, vocode.ErrNotFound) || errors.Is(err, vocode.ErrValidation) {
+			t.Fatalf("expected error: %v, got: %v", vocode.ErrNotFound, err)
+		}
+		var apiErr *vocode.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected API error, got: %T", err)
+		}
+		if apiErr.StatusCode != http.StatusNotFound || apiErr.Method != http.MethodGet || apiErr.URL != s.URL+"/v1/agents?id=missing" {
+			t.Fatalf("unexpected API error: %+v", apiErr)
+		}
+		if apiErr.Header.Get("Content-Type") != "application/json" || len(apiErr.Body) == 0 {
+			t.Fatalf("expected response header and body, got: %+v", apiErr)
+		}
+		if vocode.IsRetryable(err) {
+			t.Fatal("expected non-retryable error")
+		}
+	})
+	t.Run("validation", func(t *testing.T) {
+		t.Parallel()
+		_, err := s.Client().CreatePrompt(ctx, &vocode.CreatePromptReq{})
+		if !errors.Is(err, vocode.ErrValidation) {
+			t.Fatalf("expected error: %v, got: %v", vocode.ErrValidation, err)
+		}
+	})
+	t.Run("unauthorized", func(t *testing.T) {
+		t.Parallel()
+		_, err := s.Client(vocode.WithAPIKey("wrong")).GetUsage(ctx)
+		if !errors.Is(err, vocode.ErrUnauthorized) {
+			t.Fatalf("expected error: %v, got: %v", vocode.ErrUnauthorized, err)
+		}
+	})
+	t.Run("non_json", func(t *testing.T) {
+		t.Parallel()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		}))
+		t.Cleanup(ts.Close)
+
+		// NOTE: the retries are disabled so the error is returned right away
+		httpClient := client.NewHTTP(client.WithRetryPolicy(nil))
+		_, err := vocode.NewClient(vocode.WithBaseURL(ts.URL), vocode.WithHTTPClient(httpClient)).GetUsage(ctx)
+		var apiErr *vocode.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected API error, got: %v", err)
+		}
+		if apiErr.StatusCode != http.StatusBadGateway || string(apiErr.Body) != "bad gateway\n" || apiErr.Header.Get("Retry-After") != "1" {
+			t.Fatalf("unexpected API error: %+v", apiErr)
+		}
+		if !vocode.IsRetryable(err) {
+			t.Fatal("expected retryable error")
+		}
+	})
+}
+
+func TestFieldErrors(t *testing.T) {
+	t.Parallel()
+	t.Run("mapping", func(t *testing.T) {
+		t.Parallel()
+		paramErr := vocode.APIParamError{
+			Detail: []vocode.ParamErrorDetail{
+				{Type: "less_than_equal", Loc: []any{"body", "llm_temperature"}, Msg: "Input should be less than or equal to 1", Input: 1.5},
+				{Type: "uuid_parsing", Loc: []any{"body", "actions", float64(1)}, Msg: "Input should be a valid UUID"},
+				{Type: "missing", Loc: []any{"body", "unknown"}, Msg: "Field required"},
+			},
+		}
+		req := &vocode.CreateAgentReq{AgentReq: vocode.AgentReq{Actions: []string{"a", "b"}}}
+
+		exp := []string{"AgentReq.LLMTemperature", "AgentReq.Actions[1]", ""}
+		for i, fe := range paramErr.Fields(req) {
+			if fe.Field != exp[i] {
+				t.Fatalf("expected field: %q, got: %q", exp[i], fe.Field)
+			}
+		}
+		if exp := "actions[1]"; paramErr.Detail[1].Path() != exp {
+			t.Fatalf("expected path: %q, got: %q", exp, paramErr.Detail[1].Path())
+		}
+	})
+	t.Run("flattened", func(t *testing.T) {
+		t.Parallel()
+		paramErr := vocode.APIParamError{
+			Detail: []vocode.ParamErrorDetail{{Type: "missing", Loc: []any{"body", "speaker"}, Msg: "Field required"}},
+		}
+		req := vocode.CreateVoiceReq{VoiceReq: vocode.VoiceReq{Type: vocode.RimeVoiceType}}
+		if fields := paramErr.Fields(req); fields[0].Field != "VoiceReq.RimeVoice.Speaker" {
+			t.Fatalf("expected field: %q, got: %q", "VoiceReq.RimeVoice.Speaker", fields[0].Field)
+		}
+	})
+	t.Run("api", func(t *testing.T) {
+		t.Parallel()
+		s := vocodetest.NewServer()
+		t.Cleanup(s.Close)
+
+		req := &vocode.CreatePromptReq{}
+		_, err := s.Client().CreatePrompt(context.Background(), req)
+		fields := vocode.FieldErrors(err, req)
+		if len(fields) != 1 || fields[0].Field != "PromptReq.Content" || fields[0].Path != "content" {
+			t.Fatalf("unexpected field errors: %+v", fields)
+		}
+		if !strings.Contains(err.Error(), "content: String should have at least 1 character (string_too_short)") {
+			t.Fatalf("unexpected error message: %s", err)
+		}
+	})
+}
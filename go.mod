@@ -1,3 +1,5 @@
 module github.com/milosgajdos/go-vocode
 
 go 1.21
+
+require sigs.k8s.io/yaml v1.4.0
@@ -0,0 +1,174 @@
+// Package jsonl implements the append-only JSON lines log
+// which the stores persist their records in.
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Log is the append-only JSONL file.
+// Every value is appended as a single line
+// and synced to the disk before Append returns.
+// It is safe for concurrent use.
+type Log struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+}
+
+// Open opens the log file at path, creating it if necessary.
+// If the process crashed while appending the value, the file ends
+// with the partially written line; the line is truncated so that
+// the next value is not appended to it. The value whose line was
+// truncated had not been synced so its Append never returned.
+func Open(path string) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	err = truncatePartial(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{
+		path: path,
+		f:    f,
+	}, nil
+}
+
+// truncatePartial truncates f after its last newline.
+func truncatePartial(f *os.File) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	const chunk = 4096
+	size := info.Size()
+	buf := make([]byte, chunk)
+	for end := size; end > 0; {
+		off := max(end-chunk, 0)
+		n, err := f.ReadAt(buf[:end-off], off)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			if off+int64(i)+1 == size {
+				return nil
+			}
+			return f.Truncate(off + int64(i) + 1)
+		}
+		end = off
+	}
+	if size == 0 {
+		return nil
+	}
+	return f.Truncate(0)
+}
+
+// Load calls fn with every non-empty line of the log in the order the lines
+// were appended. The lines which fn fails to decode are corrupt: Load stops
+// and returns the error rather than skipping them and losing the records.
+func (l *Log) Load(fn func(line []byte) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if err := fn(line); err != nil {
+				return fmt.Errorf("%s: line %d: %w", l.path, n, err)
+			}
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// Append appends v encoded as JSON to the log.
+func (l *Log) Append(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return l.f.Sync()
+}
+
+// Rewrite atomically replaces the log with the values added by fn.
+func (l *Log) Rewrite(fn func(add func(v any) error) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tmp := l.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	if err := fn(enc.Encode); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return err
+	}
+
+	l.f.Close()
+	l.f, err = os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	return err
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.f.Close()
+}
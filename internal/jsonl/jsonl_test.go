@@ -0,0 +1,124 @@
+package jsonl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type record struct {
+	ID string `json:"id"`
+}
+
+func load(t *testing.T, l *Log) ([]string, error) {
+	t.Helper()
+	var ids []string
+	err := l.Load(func(line []byte) error {
+		var r record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return err
+		}
+		ids = append(ids, r.ID)
+		return nil
+	})
+	return ids, err
+}
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+	t.Run("partial_line", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "log.jsonl")
+		// the process crashed while appending the second record
+		if err := os.WriteFile(path, []byte(`{"id":"1"}`+"\n"+`{"id":`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		l, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+		if err := l.Append(record{ID: "2"}); err != nil {
+			t.Fatal(err)
+		}
+		ids, err := load(t, l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := []string{"1", "2"}; !reflect.DeepEqual(ids, exp) {
+			t.Fatalf("expected records: %v, got: %v", exp, ids)
+		}
+	})
+	t.Run("partial_only", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "log.jsonl")
+		if err := os.WriteFile(path, []byte(`{"id":"1"`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		l, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+		if err := l.Append(record{ID: "2"}); err != nil {
+			t.Fatal(err)
+		}
+		ids, err := load(t, l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := []string{"2"}; !reflect.DeepEqual(ids, exp) {
+			t.Fatalf("expected records: %v, got: %v", exp, ids)
+		}
+	})
+	t.Run("corrupt_line", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "log.jsonl")
+		if err := os.WriteFile(path, []byte(`{"id":"1"}`+"\n{\n"+`{"id":"2"}`+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		l, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+		if _, err := load(t, l); err == nil {
+			t.Fatal("expected corrupt line error")
+		}
+	})
+	t.Run("rewrite", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "log.jsonl")
+		l, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+		for _, id := range []string{"1", "1", "2"} {
+			if err := l.Append(record{ID: id}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		err = l.Rewrite(func(add func(any) error) error {
+			return add(record{ID: "3"})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Append(record{ID: "4"}); err != nil {
+			t.Fatal(err)
+		}
+		ids, err := load(t, l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := []string{"3", "4"}; !reflect.DeepEqual(ids, exp) {
+			t.Fatalf("expected records: %v, got: %v", exp, ids)
+		}
+	})
+}
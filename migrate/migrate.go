@@ -0,0 +1,281 @@
+// Package migrate copies the Vocode agents between accounts.
+//
+// Migrate resolves the resources the agents reference, i.e. their
+// prompts, voices, actions, webhooks and vector databases, and copies
+// them from the source account to the destination account before the
+// agents so that the agents reference the copied resources. The phone
+// numbers bound to the agents in the source account are bound to the
+// copied agents in the destination account.
+//
+// The IDs of the copied resources are recorded in the spec.State keyed by
+// their source IDs. When the migration is run again, the recorded resources
+// are updated if their source changed and the resources which are not
+// recorded are matched against the identical destination resources, so
+// nothing is copied twice.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/spec"
+)
+
+// Op is the migration operation.
+type Op string
+
+const (
+	// OpCreate resources are copied to the destination.
+	OpCreate Op = "create"
+	// OpUpdate resources were copied before but their source changed.
+	OpUpdate Op = "update"
+	// OpMatch resources are already in the destination.
+	OpMatch Op = "match"
+	// OpBind numbers are bound to the copied agent.
+	OpBind Op = "bind"
+	// OpSkip numbers do not exist in the destination.
+	OpSkip Op = "skip"
+)
+
+// Step is the migration step of the source resource.
+type Step struct {
+	Kind spec.Kind
+	Op   Op
+	// SourceID is the ID of the source resource.
+	// It is the phone number of the numbers.
+	SourceID string
+	// ID is the ID of the destination resource. It is empty for
+	// the resources which are not created in the dry run and for
+	// the skipped numbers.
+	ID string
+}
+
+// Result is the list of migration steps.
+type Result struct {
+	// Steps are ordered so that all the resources
+	// are copied after the resources they reference.
+	Steps []Step
+}
+
+// String returns the human readable migration result.
+func (r *Result) String() string {
+	var sb strings.Builder
+	counts := make(map[Op]int)
+	for _, s := range r.Steps {
+		counts[s.Op]++
+		switch s.Op {
+		case OpCreate:
+			fmt.Fprintf(&sb, "+ %s %s\n", s.Kind, s.SourceID)
+		case OpUpdate:
+			fmt.Fprintf(&sb, "~ %s %s -> %s\n", s.Kind, s.SourceID, s.ID)
+		case OpBind:
+			fmt.Fprintf(&sb, "> %s %s -> %s\n", s.Kind, s.SourceID, s.ID)
+		case OpSkip:
+			fmt.Fprintf(&sb, "! %s %s not in destination\n", s.Kind, s.SourceID)
+		}
+	}
+	fmt.Fprintf(&sb, "Migration: %d to create, %d to update, %d matched, %d numbers to bind, %d numbers skipped.",
+		counts[OpCreate], counts[OpUpdate], counts[OpMatch], counts[OpBind], counts[OpSkip])
+	return sb.String()
+}
+
+// Options configure the migration.
+type Options struct {
+	// DryRun reports the migration steps
+	// without changing the destination.
+	DryRun bool
+	// State records the IDs of the copied resources.
+	State *spec.State
+	// Numbers maps the source phone numbers
+	// to the destination phone numbers.
+	Numbers map[string]string
+}
+
+// Option is functional migration option.
+type Option func(*Options)
+
+// WithDryRun enables the dry run which reports
+// the steps without changing the destination.
+func WithDryRun() Option {
+	return func(o *Options) {
+		o.DryRun = true
+	}
+}
+
+// WithState sets the State which records the IDs of the copied resources.
+// The State is saved after every change so the interrupted migration can
+// be run again. By default the State is not persisted.
+func WithState(state *spec.State) Option {
+	return func(o *Options) {
+		o.State = state
+	}
+}
+
+// WithNumber binds the destination phone number to the copied agent
+// which is bound to the source phone number. By default the numbers are
+// bound only if the same phone number exists in the destination.
+func WithNumber(src, dst string) Option {
+	return func(o *Options) {
+		if o.Numbers == nil {
+			o.Numbers = make(map[string]string)
+		}
+		o.Numbers[src] = dst
+	}
+}
+
+// migrator migrates the resources between the accounts.
+type migrator struct {
+	src  *vocode.Client
+	dst  *vocode.Client
+	opts Options
+
+	result *Result
+	// live are the destination resources of the kind
+	// which are matched against the copied resources.
+	live map[spec.Kind][]liveResource
+	// ids are the destination IDs of the resolved source IDs.
+	// They're empty for the resources not created in the dry run.
+	ids map[spec.Kind]map[string]string
+}
+
+// Migrate copies the agents with the given IDs and the resources they
+// reference from the src account to the dst account and binds the phone
+// numbers to the copied agents. It returns the migration steps which
+// were made, or which would be made in the dry run.
+func Migrate(ctx context.Context, src, dst *vocode.Client, agentIDs []string, opts ...Option) (*Result, error) {
+	options := Options{
+		State: spec.NewState(),
+	}
+	for _, apply := range opts {
+		apply(&options)
+	}
+
+	m := &migrator{
+		src:    src,
+		dst:    dst,
+		opts:   options,
+		result: &Result{},
+		live:   make(map[spec.Kind][]liveResource),
+		ids:    make(map[spec.Kind]map[string]string),
+	}
+
+	copied := make(map[string]string, len(agentIDs))
+	for _, id := range agentIDs {
+		dstID, err := m.agent(ctx, id)
+		if err != nil {
+			return m.result, fmt.Errorf("agent %s: %w", id, err)
+		}
+		copied[id] = dstID
+	}
+
+	if err := m.numbers(ctx, copied); err != nil {
+		return m.result, err
+	}
+	return m.result, nil
+}
+
+// agent copies the agent and the resources it references.
+func (m *migrator) agent(ctx context.Context, id string) (string, error) {
+	a, err := m.src.GetAgent(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	req := spec.AgentReq(a)
+
+	refs := []struct {
+		field string
+		id    *string
+		copy  func(context.Context, *migrator, string) (string, error)
+	}{
+		{"prompt", &req.Prompt, prompts.copy},
+		{"voice", &req.Voice, voices.copy},
+		{"webhook", &req.Webhook, webhooks.copy},
+		{"vector_database", &req.VectorDB, vectorDBs.copy},
+	}
+	resolved := true
+	for _, r := range refs {
+		if *r.id == "" {
+			continue
+		}
+		dstID, err := r.copy(ctx, m, *r.id)
+		if err != nil {
+			return "", fmt.Errorf("%s %s: %w", r.field, *r.id, err)
+		}
+		*r.id, resolved = dstID, resolved && dstID != ""
+	}
+	for i, actionID := range req.Actions {
+		dstID, err := actions.copy(ctx, m, actionID)
+		if err != nil {
+			return "", fmt.Errorf("action %s: %w", actionID, err)
+		}
+		req.Actions[i], resolved = dstID, resolved && dstID != ""
+	}
+
+	return agents.migrate(ctx, m, id, req, resolved)
+}
+
+// numbers binds the destination numbers to the copied agents
+// which are bound to the source numbers. The copied maps
+// the source agent IDs to the destination agent IDs.
+func (m *migrator) numbers(ctx context.Context, copied map[string]string) error {
+	p := m.src.AllNumbers(ctx, nil)
+	for p.Next() {
+		n := p.Item()
+		if n.InboundAgent == nil {
+			continue
+		}
+		agentID, ok := copied[n.InboundAgent.ID]
+		if !ok {
+			continue
+		}
+		if err := m.number(ctx, n.Number, agentID); err != nil {
+			return fmt.Errorf("number %s: %w", n.Number, err)
+		}
+	}
+	return p.Err()
+}
+
+func (m *migrator) number(ctx context.Context, number, agentID string) error {
+	nr, ok := m.opts.Numbers[number]
+	if !ok {
+		nr = number
+	}
+
+	n, err := m.dst.GetNumber(ctx, nr)
+	if err != nil {
+		if errors.Is(err, vocode.ErrNotFound) {
+			m.step(spec.NumberKind, OpSkip, number, "")
+			return nil
+		}
+		return err
+	}
+	if agentID != "" && n.InboundAgent != nil && n.InboundAgent.ID == agentID {
+		m.step(spec.NumberKind, OpMatch, number, nr)
+		return nil
+	}
+	m.step(spec.NumberKind, OpBind, number, nr)
+	if m.opts.DryRun {
+		return nil
+	}
+
+	_, err = m.dst.UpdateNumber(ctx, nr, &vocode.UpdateNumberReq{
+		Label:        n.Label,
+		OutboundOnly: n.OutboundOnly,
+		InboundAgent: &vocode.Agent{ID: agentID},
+		ExampleCtx:   n.ExampleCtx,
+	})
+	return err
+}
+
+// step records the migration step.
+func (m *migrator) step(kind spec.Kind, op Op, srcID, id string) {
+	m.result.Steps = append(m.result.Steps, Step{
+		Kind:     kind,
+		Op:       op,
+		SourceID: srcID,
+		ID:       id,
+	})
+}
@@ -0,0 +1,167 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/spec"
+	"github.com/milosgajdos/go-vocode/vocodetest"
+)
+
+const srcSpec = `{
+	"prompts": {"support": {"content": "You are a support agent."}},
+	"voices": {"rime": {"type": "voice_rime", "speaker": "young_male", "speed_alpha": 1.2}},
+	"actions": {
+		"hangup": {
+			"type": "action_end_conversation",
+			"action_trigger": {"type": "action_trigger_phrase_based", "config": {"phrase_triggers": [{"phrase": "bye", "conditions": ["phrase_condition_type_contains"]}]}},
+			"config": {}
+		}
+	},
+	"webhooks": {"events": {"subscriptions": ["event_message"], "url": "https://example.com/hook", "method": "POST"}},
+	"agents": {"support": {"name": "Support", "prompt": "support", "voice": "rime", "actions": ["hangup"], "webhook": "events", "language": "en"}},
+	"numbers": {%q: {"label": "support line", "inbound_agent": "support"}}
+}`
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	srcServer := vocodetest.NewServer()
+	t.Cleanup(srcServer.Close)
+	dstServer := vocodetest.NewServer()
+	t.Cleanup(dstServer.Close)
+
+	src, dst := srcServer.Client(), dstServer.Client()
+	ctx := context.Background()
+
+	buy := func(c *vocode.Client) string {
+		t.Helper()
+		n, err := c.BuyNumber(ctx, &vocode.BuyNumberReq{AreaCode: "415", TelProvider: vocode.TwilioTelProvider})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n.Number
+	}
+	srcNumber, dstNumber := buy(src), buy(dst)
+
+	sp, err := spec.Decode(strings.NewReader(fmt.Sprintf(srcSpec, srcNumber)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srcState := spec.NewState()
+	p, err := sp.Plan(ctx, src, srcState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(ctx, src, srcState); err != nil {
+		t.Fatal(err)
+	}
+	agentID, _ := srcState.ID(spec.AgentKind, "support")
+
+	statePath := filepath.Join(t.TempDir(), "migrate.json")
+	state, err := spec.LoadState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ops := func(r *Result) map[string]Op {
+		ops := make(map[string]Op)
+		for _, s := range r.Steps {
+			ops[string(s.Kind)] = s.Op
+		}
+		return ops
+	}
+	migrate := func(opts ...Option) *Result {
+		t.Helper()
+		opts = append(opts, WithNumber(srcNumber, dstNumber))
+		r, err := Migrate(ctx, src, dst, []string{agentID}, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	t.Run("dry_run", func(t *testing.T) {
+		r := migrate(WithDryRun(), WithState(state))
+		exp := map[string]Op{"prompt": OpCreate, "voice": OpCreate, "action": OpCreate, "webhook": OpCreate, "agent": OpCreate, "number": OpBind}
+		if got := ops(r); !reflect.DeepEqual(got, exp) {
+			t.Fatalf("expected ops: %v, got: %v", exp, got)
+		}
+		if _, ok := state.ID(spec.AgentKind, agentID); ok {
+			t.Fatal("expected no state changes in the dry run")
+		}
+		if agents, _ := dst.ListAgents(ctx, nil); len(agents.Items) != 0 {
+			t.Fatalf("expected no destination agents, got: %d", len(agents.Items))
+		}
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		r := migrate(WithState(state))
+		if !strings.HasSuffix(r.String(), "Migration: 5 to create, 0 to update, 0 matched, 1 numbers to bind, 0 numbers skipped.") {
+			t.Fatalf("unexpected result:\n%s", r)
+		}
+
+		// the copied agent references the copied resources
+		dstAgentID, _ := state.ID(spec.AgentKind, agentID)
+		agent, err := dst.GetAgent(ctx, dstAgentID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		srcPromptID, _ := srcState.ID(spec.PromptKind, "support")
+		dstPromptID, _ := state.ID(spec.PromptKind, srcPromptID)
+		if agent.Prompt == nil || agent.Prompt.ID != dstPromptID || dstPromptID == srcPromptID {
+			t.Fatalf("expected agent prompt: %s, got: %+v", dstPromptID, agent.Prompt)
+		}
+		if len(agent.Actions) != 1 || agent.Voice == nil || agent.Webhook == nil {
+			t.Fatalf("unexpected agent: %+v", agent)
+		}
+		n, err := dst.GetNumber(ctx, dstNumber)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.InboundAgent == nil || n.InboundAgent.ID != dstAgentID {
+			t.Fatalf("expected number agent: %s, got: %+v", dstAgentID, n.InboundAgent)
+		}
+	})
+
+	t.Run("rerun", func(t *testing.T) {
+		state, err := spec.LoadState(statePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the copied resources are matched with and without the state
+		for _, s := range []*spec.State{state, spec.NewState()} {
+			r := migrate(WithState(s))
+			exp := map[string]Op{"prompt": OpMatch, "voice": OpMatch, "action": OpMatch, "webhook": OpMatch, "agent": OpMatch, "number": OpMatch}
+			if got := ops(r); !reflect.DeepEqual(got, exp) {
+				t.Fatalf("expected ops: %v, got: %v", exp, got)
+			}
+		}
+		if agents, _ := dst.ListAgents(ctx, nil); len(agents.Items) != 1 {
+			t.Fatalf("expected 1 destination agent, got: %d", len(agents.Items))
+		}
+
+		// the changed source is updated
+		promptID, _ := srcState.ID(spec.PromptKind, "support")
+		if _, err := src.UpdatePrompt(ctx, promptID, &vocode.UpdatePromptReq{PromptReq: vocode.PromptReq{Content: "You are a sales agent."}}); err != nil {
+			t.Fatal(err)
+		}
+		r := migrate(WithState(state))
+		if got := ops(r); got["prompt"] != OpUpdate || got["agent"] != OpMatch {
+			t.Fatalf("expected prompt update, got: %v", got)
+		}
+		dstPromptID, _ := state.ID(spec.PromptKind, promptID)
+		prompt, err := dst.GetPrompt(ctx, dstPromptID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prompt.Content != "You are a sales agent." {
+			t.Fatalf("expected prompt content: %q, got: %q", "You are a sales agent.", prompt.Content)
+		}
+	})
+}
@@ -0,0 +1,235 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/spec"
+)
+
+// liveResource is the destination resource
+// which the copied resources are matched against.
+type liveResource struct {
+	id  string
+	req []byte
+}
+
+// resource migrates the resources T created from the request R.
+type resource[T, R any] struct {
+	kind spec.Kind
+	id   func(*T) string
+	// req returns the request which recreates the resource.
+	req    func(*T) R
+	get    func(*vocode.Client, context.Context, string) (*T, error)
+	all    func(*vocode.Client, context.Context, *vocode.ListOptions) *vocode.Pager[T]
+	create func(context.Context, *vocode.Client, *R) (*T, error)
+	update func(context.Context, *vocode.Client, string, *R) (*T, error)
+}
+
+// copy migrates the source resource which references no other resources.
+func (r *resource[T, R]) copy(ctx context.Context, m *migrator, srcID string) (string, error) {
+	if id, ok := m.ids[r.kind][srcID]; ok {
+		return id, nil
+	}
+	item, err := r.get(m.src, ctx, srcID)
+	if err != nil {
+		return "", err
+	}
+	return r.migrate(ctx, m, srcID, r.req(item), true)
+}
+
+// migrate migrates the source resource recreated by req and returns the
+// destination ID. The resource whose references are not resolved because
+// they're not created in the dry run can't be matched so it's created.
+func (r *resource[T, R]) migrate(ctx context.Context, m *migrator, srcID string, req R, resolved bool) (string, error) {
+	if id, ok := m.ids[r.kind][srcID]; ok {
+		return id, nil
+	}
+	want, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	if id, ok := m.opts.State.ID(r.kind, srcID); ok {
+		live, err := r.get(m.dst, ctx, id)
+		switch {
+		case err == nil:
+			got, err := json.Marshal(r.req(live))
+			if err != nil {
+				return "", err
+			}
+			if resolved && bytes.Equal(want, got) {
+				return id, m.record(r.kind, OpMatch, srcID, id)
+			}
+			if !m.opts.DryRun {
+				if _, err := r.update(ctx, m.dst, id, &req); err != nil {
+					return "", err
+				}
+			}
+			return id, m.record(r.kind, OpUpdate, srcID, id)
+		case errors.Is(err, vocode.ErrNotFound):
+			// NOTE: the copied resource has been deleted so it's copied again
+		default:
+			return "", err
+		}
+	}
+
+	if resolved {
+		live, err := r.live(ctx, m)
+		if err != nil {
+			return "", err
+		}
+		for _, l := range live {
+			if !m.claimed(r.kind, l.id) && bytes.Equal(want, l.req) {
+				return l.id, m.record(r.kind, OpMatch, srcID, l.id)
+			}
+		}
+	}
+
+	if m.opts.DryRun {
+		return "", m.record(r.kind, OpCreate, srcID, "")
+	}
+	item, err := r.create(ctx, m.dst, &req)
+	if err != nil {
+		return "", err
+	}
+	return r.id(item), m.record(r.kind, OpCreate, srcID, r.id(item))
+}
+
+// live returns the destination resources of the kind.
+// They're listed only once per migration.
+func (r *resource[T, R]) live(ctx context.Context, m *migrator) ([]liveResource, error) {
+	if live, ok := m.live[r.kind]; ok {
+		return live, nil
+	}
+
+	live := []liveResource{}
+	p := r.all(m.dst, ctx, nil)
+	for p.Next() {
+		item := p.Item()
+		req, err := json.Marshal(r.req(&item))
+		if err != nil {
+			return nil, err
+		}
+		live = append(live, liveResource{id: r.id(&item), req: req})
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	m.live[r.kind] = live
+	return live, nil
+}
+
+// record records the migration step of the resource and the destination
+// ID of the source resource. The State is saved unless it's the dry run.
+func (m *migrator) record(kind spec.Kind, op Op, srcID, id string) error {
+	m.step(kind, op, srcID, id)
+	if m.ids[kind] == nil {
+		m.ids[kind] = make(map[string]string)
+	}
+	m.ids[kind][srcID] = id
+
+	if m.opts.DryRun {
+		return nil
+	}
+	m.opts.State.Set(kind, srcID, id)
+	return m.opts.State.Save()
+}
+
+// claimed returns true if the destination resource
+// has already been matched or copied by the migration.
+func (m *migrator) claimed(kind spec.Kind, id string) bool {
+	for _, dstID := range m.ids[kind] {
+		if dstID == id {
+			return true
+		}
+	}
+	return false
+}
+
+var prompts = &resource[vocode.Prompt, vocode.PromptReq]{
+	kind: spec.PromptKind,
+	id:   func(p *vocode.Prompt) string { return p.ID },
+	req:  spec.PromptReq,
+	get:  (*vocode.Client).GetPrompt,
+	all:  (*vocode.Client).AllPrompts,
+	create: func(ctx context.Context, c *vocode.Client, r *vocode.PromptReq) (*vocode.Prompt, error) {
+		return c.CreatePrompt(ctx, &vocode.CreatePromptReq{PromptReq: *r})
+	},
+	update: func(ctx context.Context, c *vocode.Client, id string, r *vocode.PromptReq) (*vocode.Prompt, error) {
+		return c.UpdatePrompt(ctx, id, &vocode.UpdatePromptReq{PromptReq: *r})
+	},
+}
+
+var voices = &resource[vocode.Voice, vocode.VoiceReq]{
+	kind: spec.VoiceKind,
+	id:   func(v *vocode.Voice) string { return v.ID },
+	req:  spec.VoiceReq,
+	get:  (*vocode.Client).GetVoice,
+	all:  (*vocode.Client).AllVoices,
+	create: func(ctx context.Context, c *vocode.Client, r *vocode.VoiceReq) (*vocode.Voice, error) {
+		return c.CreateVoice(ctx, &vocode.CreateVoiceReq{VoiceReq: *r})
+	},
+	update: func(ctx context.Context, c *vocode.Client, id string, r *vocode.VoiceReq) (*vocode.Voice, error) {
+		return c.UpdateVoice(ctx, id, &vocode.UpdateVoiceReq{VoiceReq: *r})
+	},
+}
+
+var actions = &resource[vocode.Action, vocode.ActionReq]{
+	kind: spec.ActionKind,
+	id:   func(a *vocode.Action) string { return a.ID },
+	req:  spec.ActionReq,
+	get:  (*vocode.Client).GetAction,
+	all:  (*vocode.Client).AllActions,
+	create: func(ctx context.Context, c *vocode.Client, r *vocode.ActionReq) (*vocode.Action, error) {
+		return c.CreateAction(ctx, &vocode.CreateActionReq{ActionReq: *r})
+	},
+	update: func(ctx context.Context, c *vocode.Client, id string, r *vocode.ActionReq) (*vocode.Action, error) {
+		return c.UpdateAction(ctx, id, &vocode.UpdateActionReq{ActionReq: *r})
+	},
+}
+
+var webhooks = &resource[vocode.Webhook, vocode.WebhookReq]{
+	kind: spec.WebhookKind,
+	id:   func(w *vocode.Webhook) string { return w.ID },
+	req:  spec.WebhookReq,
+	get:  (*vocode.Client).GetWebhook,
+	all:  (*vocode.Client).AllWebhooks,
+	create: func(ctx context.Context, c *vocode.Client, r *vocode.WebhookReq) (*vocode.Webhook, error) {
+		return c.CreateWebhook(ctx, &vocode.CreateWebhookReq{WebhookReq: *r})
+	},
+	update: func(ctx context.Context, c *vocode.Client, id string, r *vocode.WebhookReq) (*vocode.Webhook, error) {
+		return c.UpdateWebhook(ctx, id, &vocode.UpdateWebhookReq{WebhookReq: *r})
+	},
+}
+
+var vectorDBs = &resource[vocode.VectorDB, vocode.VectorDBReq]{
+	kind: spec.VectorDBKind,
+	id:   func(v *vocode.VectorDB) string { return v.ID },
+	req:  spec.VectorDBReq,
+	get:  (*vocode.Client).GetVectorDB,
+	all:  (*vocode.Client).AllVectorDBs,
+	create: func(ctx context.Context, c *vocode.Client, r *vocode.VectorDBReq) (*vocode.VectorDB, error) {
+		return c.CreateVectorDB(ctx, &vocode.CreateVectorDBReq{VectorDBReq: *r})
+	},
+	update: func(ctx context.Context, c *vocode.Client, id string, r *vocode.VectorDBReq) (*vocode.VectorDB, error) {
+		return c.UpdateVectorDB(ctx, id, &vocode.UpdateVectorDBReq{VectorDBReq: *r})
+	},
+}
+
+var agents = &resource[vocode.Agent, vocode.AgentReq]{
+	kind: spec.AgentKind,
+	id:   func(a *vocode.Agent) string { return a.ID },
+	req:  spec.AgentReq,
+	get:  (*vocode.Client).GetAgent,
+	all:  (*vocode.Client).AllAgents,
+	create: func(ctx context.Context, c *vocode.Client, r *vocode.AgentReq) (*vocode.Agent, error) {
+		return c.CreateAgent(ctx, &vocode.CreateAgentReq{AgentReq: *r})
+	},
+	update: func(ctx context.Context, c *vocode.Client, id string, r *vocode.AgentReq) (*vocode.Agent, error) {
+		return c.UpdateAgent(ctx, id, &vocode.UpdateAgentReq{AgentReq: *r})
+	},
+}
@@ -54,11 +54,9 @@ func (c *Client) ListNumbers(ctx context.Context, paging *PageParams) (*Numbers,
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
+	var options []request.HTTPOption
 	if paging != nil {
-		request.WithPageParams(paging.Encode())
+		options = append(options, request.WithPageParams(paging.Encode()))
 	}
 
 	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
@@ -66,7 +64,7 @@ func (c *Client) ListNumbers(ctx context.Context, paging *PageParams) (*Numbers,
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -79,17 +77,24 @@ func (c *Client) ListNumbers(ctx context.Context, paging *PageParams) (*Numbers,
 	return numbers, nil
 }
 
+// AllNumbers returns Pager which iterates over all the phone numbers.
+func (c *Client) AllNumbers(ctx context.Context, opts *ListOptions) *Pager[Number] {
+	return newPager(ctx, opts, func(ctx context.Context, paging *PageParams) ([]Number, *Paging, error) {
+		page, err := c.ListNumbers(ctx, paging)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Items, page.Paging, nil
+	})
+}
+
 func (c *Client) GetNumber(ctx context.Context, phoneNr string) (*Number, error) {
 	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/numbers")
 	if err != nil {
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
+	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +102,7 @@ func (c *Client) GetNumber(ctx context.Context, phoneNr string) (*Number, error)
 	q.Add("phone_number", phoneNr)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -123,16 +128,12 @@ func (c *Client) BuyNumber(ctx context.Context, buyReq *BuyNumberReq) (*Number,
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -158,11 +159,7 @@ func (c *Client) UpdateNumber(ctx context.Context, phoneNr string, updateReq *Up
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -170,7 +167,7 @@ func (c *Client) UpdateNumber(ctx context.Context, phoneNr string, updateReq *Up
 	q.Add("phone_number", phoneNr)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -189,11 +186,7 @@ func (c *Client) CancelNumber(ctx context.Context, phoneNr string) (*Number, err
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), nil, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -201,7 +194,7 @@ func (c *Client) CancelNumber(ctx context.Context, phoneNr string) (*Number, err
 	q.Add("phone_number", phoneNr)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,115 @@
+package vocode
+
+import (
+	"context"
+)
+
+// ListOptions configure the list pagers.
+type ListOptions struct {
+	// Size is the number of items fetched per page.
+	// The API default page size is used if it's not set.
+	Size int
+	// Sort sets the item sort order.
+	Sort *Sort
+	// Limit stops the paging after Limit items.
+	// There is no limit if it's not set.
+	Limit int
+}
+
+// pageFunc fetches the items of the page.
+type pageFunc[T any] func(ctx context.Context, paging *PageParams) ([]T, *Paging, error)
+
+// Pager iterates over all the items of the paged list.
+// It fetches the next page once all the items of the
+// current page have been consumed and stops when the
+// API reports there are no more pages, the item limit
+// has been reached, or the context has been cancelled.
+//
+//	p := client.AllCalls(ctx, nil)
+//	for p.Next() {
+//		call := p.Item()
+//	}
+//	if err := p.Err(); err != nil {
+//		return err
+//	}
+type Pager[T any] struct {
+	ctx    context.Context
+	fetch  pageFunc[T]
+	params PageParams
+	limit  int
+
+	items   []T
+	idx     int
+	item    T
+	count   int
+	fetched bool
+	more    bool
+	err     error
+}
+
+func newPager[T any](ctx context.Context, opts *ListOptions, fetch pageFunc[T]) *Pager[T] {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	p := &Pager[T]{
+		ctx:   ctx,
+		fetch: fetch,
+	}
+	if opts != nil {
+		p.params.Size = opts.Size
+		p.params.Sort = opts.Sort
+		p.limit = opts.Limit
+	}
+	return p
+}
+
+// Next advances the pager to the next item which is then available via Item.
+// It returns false when there are no more items or when an error occurs.
+func (p *Pager[T]) Next() bool {
+	if p.err != nil {
+		return false
+	}
+	if p.limit > 0 && p.count >= p.limit {
+		return false
+	}
+	if err := p.ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+
+	for p.idx >= len(p.items) {
+		if p.fetched && !p.more {
+			return false
+		}
+		p.params.Page++
+		items, paging, err := p.fetch(p.ctx, &p.params)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.fetched = true
+		p.items, p.idx = items, 0
+		p.more = paging != nil && paging.HasMore && len(items) > 0
+	}
+
+	p.item = p.items[p.idx]
+	p.idx++
+	p.count++
+
+	return true
+}
+
+// Item returns the current item.
+func (p *Pager[T]) Item() T {
+	return p.item
+}
+
+// Err returns the error which stopped the pager, if any.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// Page returns the number of the last fetched page.
+func (p *Pager[T]) Page() int {
+	return p.params.Page
+}
@@ -0,0 +1,75 @@
+package vocode_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/vocodetest"
+)
+
+func TestPager(t *testing.T) {
+	t.Parallel()
+
+	s := vocodetest.NewServer()
+	t.Cleanup(s.Close)
+	c := s.Client()
+
+	for i := 0; i < 7; i++ {
+		_, err := c.CreatePrompt(context.Background(), &vocode.CreatePromptReq{
+			PromptReq: vocode.PromptReq{Content: fmt.Sprintf("prompt %d", i)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("all", func(t *testing.T) {
+		t.Parallel()
+		p := c.AllPrompts(context.Background(), &vocode.ListOptions{Size: 3, Sort: &vocode.Sort{Col: "content", Desc: true}})
+		var got []string
+		for p.Next() {
+			got = append(got, p.Item().Content)
+		}
+		if err := p.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 7 || got[0] != "prompt 6" || got[6] != "prompt 0" {
+			t.Fatalf("unexpected prompts: %v", got)
+		}
+		if p.Page() != 3 {
+			t.Fatalf("expected pages: %d, got: %d", 3, p.Page())
+		}
+	})
+	t.Run("limit", func(t *testing.T) {
+		t.Parallel()
+		p := c.AllPrompts(context.Background(), &vocode.ListOptions{Size: 2, Limit: 3})
+		count := 0
+		for p.Next() {
+			count++
+		}
+		if err := p.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if count != 3 || p.Page() != 2 {
+			t.Fatalf("expected 3 items from 2 pages, got: %d items from %d pages", count, p.Page())
+		}
+	})
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		p := c.AllPrompts(ctx, &vocode.ListOptions{Size: 2})
+		if !p.Next() {
+			t.Fatalf("expected item, got: %v", p.Err())
+		}
+		cancel()
+		if p.Next() {
+			t.Fatal("expected pager to stop")
+		}
+		if err := p.Err(); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected error: %v, got: %v", context.Canceled, err)
+		}
+	})
+}
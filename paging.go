@@ -25,11 +25,19 @@ type PageParams struct {
 	Sort *Sort
 }
 
+// Encode encodes paging params into request params.
+// Zero page and size and nil sort are omitted.
 func (l *PageParams) Encode() request.PageParams {
 	params := map[string]string{}
-	params["page"] = fmt.Sprintf("%d", l.Page)
-	params["size"] = fmt.Sprintf("%d", l.Size)
-	params["sort_column"] = l.Sort.Col
-	params["sort_desc"] = fmt.Sprintf("%v", l.Sort.Desc)
+	if l.Page > 0 {
+		params["page"] = fmt.Sprintf("%d", l.Page)
+	}
+	if l.Size > 0 {
+		params["size"] = fmt.Sprintf("%d", l.Size)
+	}
+	if l.Sort != nil {
+		params["sort_column"] = l.Sort.Col
+		params["sort_desc"] = fmt.Sprintf("%v", l.Sort.Desc)
+	}
 	return params
 }
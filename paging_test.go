@@ -0,0 +1,80 @@
+package vocode_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/request"
+)
+
+func TestPageParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("encode", func(t *testing.T) {
+		t.Parallel()
+		testCases := []struct {
+			name   string
+			params vocode.PageParams
+			exp    request.PageParams
+		}{
+			{"zero", vocode.PageParams{}, request.PageParams{}},
+			{"page_size", vocode.PageParams{Page: 2, Size: 10}, request.PageParams{"page": "2", "size": "10"}},
+			{"sort", vocode.PageParams{Sort: &vocode.Sort{Col: "name", Desc: true}}, request.PageParams{"sort_column": "name", "sort_desc": "true"}},
+		}
+		for _, tc := range testCases {
+			if got := tc.params.Encode(); !reflect.DeepEqual(got, tc.exp) {
+				t.Fatalf("%s: expected params: %v, got: %v", tc.name, tc.exp, got)
+			}
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			mu    sync.Mutex
+			query url.Values
+		)
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			query = r.URL.Query()
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"items":[],"page":2,"size":5}`))
+		}))
+		t.Cleanup(s.Close)
+
+		c := vocode.NewClient(vocode.WithBaseURL(s.URL), vocode.WithAPIKey("key"))
+		ctx := context.Background()
+		paging := &vocode.PageParams{Page: 2, Size: 5}
+
+		lists := map[string]func() error{
+			"account_connections": func() error { _, err := c.ListAccountConns(ctx, paging); return err },
+			"actions":             func() error { _, err := c.ListActions(ctx, paging); return err },
+			"agents":              func() error { _, err := c.ListAgents(ctx, paging); return err },
+			"calls":               func() error { _, err := c.ListCalls(ctx, paging); return err },
+			"numbers":             func() error { _, err := c.ListNumbers(ctx, paging); return err },
+			"prompts":             func() error { _, err := c.ListPrompts(ctx, paging); return err },
+			"vector_databases":    func() error { _, err := c.ListVectorDBs(ctx, paging); return err },
+			"voices":              func() error { _, err := c.ListVoices(ctx, paging); return err },
+			"webhooks":            func() error { _, err := c.ListWebhooks(ctx, paging); return err },
+		}
+		for name, list := range lists {
+			if err := list(); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			mu.Lock()
+			page, size := query.Get("page"), query.Get("size")
+			mu.Unlock()
+			if page != "2" || size != "5" {
+				t.Fatalf("%s: expected page params: 2 5, got: %q %q", name, page, size)
+			}
+		}
+	})
+}
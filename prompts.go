@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/url"
 
@@ -85,11 +86,9 @@ func (c *Client) ListPrompts(ctx context.Context, paging *PageParams) (*Prompts,
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
+	var options []request.HTTPOption
 	if paging != nil {
-		request.WithPageParams(paging.Encode())
+		options = append(options, request.WithPageParams(paging.Encode()))
 	}
 
 	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
@@ -97,7 +96,7 @@ func (c *Client) ListPrompts(ctx context.Context, paging *PageParams) (*Prompts,
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -110,17 +109,24 @@ func (c *Client) ListPrompts(ctx context.Context, paging *PageParams) (*Prompts,
 	return prompts, nil
 }
 
+// AllPrompts returns Pager which iterates over all the prompts.
+func (c *Client) AllPrompts(ctx context.Context, opts *ListOptions) *Pager[Prompt] {
+	return newPager(ctx, opts, func(ctx context.Context, paging *PageParams) ([]Prompt, *Paging, error) {
+		page, err := c.ListPrompts(ctx, paging)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Items, page.Paging, nil
+	})
+}
+
 func (c *Client) GetPrompt(ctx context.Context, id string) (*Prompt, error) {
 	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/prompts")
 	if err != nil {
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
+	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +134,7 @@ func (c *Client) GetPrompt(ctx context.Context, id string) (*Prompt, error) {
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -154,16 +160,12 @@ func (c *Client) CreatePrompt(ctx context.Context, createReq *CreatePromptReq) (
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -189,11 +191,7 @@ func (c *Client) UpdatePrompt(ctx context.Context, id string, updateReq *UpdateP
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -201,7 +199,7 @@ func (c *Client) UpdatePrompt(ctx context.Context, id string, updateReq *UpdateP
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -213,3 +211,29 @@ func (c *Client) UpdatePrompt(ctx context.Context, id string, updateReq *UpdateP
 	}
 	return prompt, nil
 }
+
+// DeletePrompt deletes the prompt with the given id.
+// It returns error matching ErrNotFound if the prompt does not exist.
+func (c *Client) DeletePrompt(ctx context.Context, id string) error {
+	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/prompts/delete")
+	if err != nil {
+		return err
+	}
+
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	q.Add("id", id)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
@@ -0,0 +1,288 @@
+package vocode
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/milosgajdos/go-vocode/request"
+)
+
+const (
+	// PartialExt is the extension of the partially downloaded recordings.
+	PartialExt = ".part"
+	// downloadAttempts is the maximum number of attempts to resume
+	// the interrupted recording download within a single call.
+	downloadAttempts = 3
+)
+
+var (
+	// ErrIncompleteDownload is returned when the downloaded recording
+	// size does not match the size reported by the API.
+	ErrIncompleteDownload = errors.New("incomplete download")
+	// errRestart restarts the download of the invalid partial recording.
+	errRestart = errors.New("invalid partial recording")
+)
+
+// AudioFormat is the call recording audio format.
+type AudioFormat string
+
+const (
+	WAVFormat     AudioFormat = "wav"
+	MP3Format     AudioFormat = "mp3"
+	OggFormat     AudioFormat = "ogg"
+	FLACFormat    AudioFormat = "flac"
+	WebMFormat    AudioFormat = "webm"
+	M4AFormat     AudioFormat = "m4a"
+	UnknownFormat AudioFormat = "bin"
+)
+
+// audioContentTypes maps the audio media types to formats.
+var audioContentTypes = map[string]AudioFormat{
+	"audio/wav":      WAVFormat,
+	"audio/wave":     WAVFormat,
+	"audio/x-wav":    WAVFormat,
+	"audio/vnd.wave": WAVFormat,
+	"audio/mpeg":     MP3Format,
+	"audio/mp3":      MP3Format,
+	"audio/ogg":      OggFormat,
+	"audio/flac":     FLACFormat,
+	"audio/x-flac":   FLACFormat,
+	"audio/webm":     WebMFormat,
+	"audio/mp4":      M4AFormat,
+	"audio/x-m4a":    M4AFormat,
+}
+
+// DetectAudioFormat detects the audio format from the leading bytes
+// of the audio data. If the data is not recognised, the format is
+// derived from the content type. It returns UnknownFormat otherwise.
+func DetectAudioFormat(head []byte, contentType string) AudioFormat {
+	switch {
+	case len(head) >= 12 && bytes.Equal(head[:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WAVE")):
+		return WAVFormat
+	case bytes.HasPrefix(head, []byte("ID3")), len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0:
+		return MP3Format
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return OggFormat
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return FLACFormat
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return WebMFormat
+	case len(head) >= 8 && bytes.Equal(head[4:8], []byte("ftyp")):
+		return M4AFormat
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if f, ok := audioContentTypes[mediaType]; ok {
+			return f
+		}
+	}
+	return UnknownFormat
+}
+
+// Recording is the downloaded call recording.
+type Recording struct {
+	// Path is the path of the recording file.
+	Path string
+	// Format is the detected recording audio format.
+	Format AudioFormat
+	// ContentType is the content type reported by the API.
+	ContentType string
+	// Size is the recording size in bytes.
+	Size int64
+	// SHA256 is the hex encoded SHA-256 checksum of the recording.
+	SHA256 string
+	// Resumed is true if a partial download was resumed.
+	Resumed bool
+}
+
+// DownloadRecording downloads the recording of the call to path.
+// If path has no extension, the extension of the detected audio
+// format is appended to it. The recording is downloaded into
+// path with the PartialExt extension which is renamed to the final
+// path once the download is complete and its size verified.
+// Interrupted downloads are resumed via HTTP Range requests,
+// both within the call and by calling DownloadRecording again.
+func (c *Client) DownloadRecording(ctx context.Context, id, path string) (*Recording, error) {
+	partial := path + PartialExt
+	f, err := os.OpenFile(partial, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	defer func() {
+		if f == nil {
+			return
+		}
+		f.Close()
+		// NOTE: partial downloads are kept so they can be resumed
+		if offset == 0 {
+			os.Remove(partial)
+		}
+	}()
+
+	rec := &Recording{Resumed: offset > 0}
+	for attempt := 1; ; attempt++ {
+		var size int64
+		var done bool
+		offset, size, done, err = c.downloadRange(ctx, id, f, offset, rec)
+		if err != nil {
+			var apiErr *APIError
+			if errors.As(err, &apiErr) || ctx.Err() != nil || attempt >= downloadAttempts {
+				return nil, err
+			}
+			rec.Resumed = rec.Resumed || offset > 0
+			continue
+		}
+		if !done && size >= 0 && offset != size {
+			return nil, fmt.Errorf("%w: got %d of %d bytes", ErrIncompleteDownload, offset, size)
+		}
+		break
+	}
+
+	head := make([]byte, 12)
+	n, err := f.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	rec.Format = DetectAudioFormat(head[:n], rec.ContentType)
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	if rec.Size, err = io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	rec.SHA256 = hex.EncodeToString(h.Sum(nil))
+
+	if err := f.Sync(); err != nil {
+		return nil, err
+	}
+	err = f.Close()
+	f = nil
+	if err != nil {
+		return nil, err
+	}
+
+	rec.Path = path
+	if filepath.Ext(path) == "" {
+		rec.Path = path + "." + string(rec.Format)
+	}
+	if err := os.Rename(partial, rec.Path); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// downloadRange downloads the recording from offset and appends it to f.
+// It returns the new offset and the total recording size, or -1 if it's
+// not known. It returns true if the partial recording was already complete.
+func (c *Client) downloadRange(ctx context.Context, id string, f *os.File, offset int64, rec *Recording) (int64, int64, bool, error) {
+	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/calls/recording")
+	if err != nil {
+		return offset, -1, false, err
+	}
+
+	var options []request.HTTPOption
+	if offset > 0 {
+		options = append(options, request.WithSetHeader("Range", fmt.Sprintf("bytes=%d-", offset)))
+	}
+
+	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
+	if err != nil {
+		return offset, -1, false, err
+	}
+	q := req.URL.Query()
+	q.Add("id", id)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		var apiErr *APIError
+		// NOTE: the partial recording is complete if the range starts at its end.
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0 {
+			if _, size, ok := contentRange(apiErr.Header.Get("Content-Range")); ok && size == offset {
+				return offset, size, true, nil
+			}
+			// the partial recording is not valid so start over
+			if err := f.Truncate(0); err != nil {
+				return offset, -1, false, err
+			}
+			return 0, -1, false, errRestart
+		}
+		return offset, -1, false, err
+	}
+	defer resp.Body.Close()
+
+	rec.ContentType = resp.Header.Get("Content-Type")
+	size := int64(-1)
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, total, ok := contentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return offset, -1, false, fmt.Errorf("unexpected content range: %q", resp.Header.Get("Content-Range"))
+		}
+		size = total
+	default:
+		// the server ignored the range so start over
+		if offset > 0 {
+			if err := f.Truncate(0); err != nil {
+				return offset, -1, false, err
+			}
+			offset = 0
+			rec.Resumed = false
+		}
+		size = resp.ContentLength
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, size, false, err
+	}
+	n, err := io.Copy(f, resp.Body)
+	return offset + n, size, false, err
+}
+
+// contentRange parses the Content-Range header
+// and returns the range start and the total size.
+func contentRange(s string) (int64, int64, bool) {
+	s, ok := strings.CutPrefix(s, "bytes ")
+	if !ok {
+		return 0, 0, false
+	}
+	rng, total, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, 0, false
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if rng == "*" {
+		return 0, size, true
+	}
+	first, _, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
@@ -0,0 +1,163 @@
+package vocode_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/client"
+	"github.com/milosgajdos/go-vocode/vocodetest"
+)
+
+// dropTransport cuts the first response body after n bytes.
+type dropTransport struct {
+	n       int64
+	dropped atomic.Bool
+}
+
+func (t *dropTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil || !t.dropped.CompareAndSwap(false, true) {
+		return resp, err
+	}
+	resp.Body = &dropReader{r: io.LimitReader(resp.Body, t.n), c: resp.Body}
+	return resp, nil
+}
+
+type dropReader struct {
+	r io.Reader
+	c io.Closer
+}
+
+func (r *dropReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (r *dropReader) Close() error { return r.c.Close() }
+
+func TestDownloadRecording(t *testing.T) {
+	t.Parallel()
+
+	// MP3 served as audio/wav: the magic bytes take precedence
+	data := append([]byte("ID3\x04\x00\x00\x00\x00\x00\x00"), bytes.Repeat([]byte{0xAB}, 4096)...)
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	s := vocodetest.NewServer()
+	t.Cleanup(s.Close)
+	call := createCall(t, s.Client(), "+15550001")
+	if err := s.SetRecording(call.ID, data); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	check := func(t *testing.T, rec *vocode.Recording, path string, resumed bool) {
+		t.Helper()
+		if rec.Path != path || rec.Format != vocode.MP3Format || rec.Size != int64(len(data)) || rec.SHA256 != checksum {
+			t.Fatalf("unexpected recording: %+v", rec)
+		}
+		if rec.Resumed != resumed {
+			t.Fatalf("expected resumed: %v, got: %v", resumed, rec.Resumed)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatal("recording content mismatch")
+		}
+		if _, err := os.Stat(path + vocode.PartialExt); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected partial recording to be removed, got: %v", err)
+		}
+	}
+
+	t.Run("full", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "rec")
+		rec, err := s.Client().DownloadRecording(ctx, call.ID, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, rec, path+".mp3", false)
+	})
+	t.Run("resume", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "rec.mp3")
+		if err := os.WriteFile(path+vocode.PartialExt, data[:1000], 0o644); err != nil {
+			t.Fatal(err)
+		}
+		rec, err := s.Client().DownloadRecording(ctx, call.ID, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, rec, path, true)
+	})
+	t.Run("complete_partial", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "rec.mp3")
+		if err := os.WriteFile(path+vocode.PartialExt, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		rec, err := s.Client().DownloadRecording(ctx, call.ID, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, rec, path, true)
+	})
+	t.Run("dropped", func(t *testing.T) {
+		t.Parallel()
+		httpClient := client.NewHTTP(client.WithHTTPClient(&http.Client{Transport: &dropTransport{n: 100}}))
+		path := filepath.Join(t.TempDir(), "rec.mp3")
+		rec, err := s.Client(vocode.WithHTTPClient(httpClient)).DownloadRecording(ctx, call.ID, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, rec, path, true)
+	})
+	t.Run("not_found", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "rec.mp3")
+		_, err := s.Client().DownloadRecording(ctx, "missing", path)
+		if !errors.Is(err, vocode.ErrNotFound) {
+			t.Fatalf("expected error: %v, got: %v", vocode.ErrNotFound, err)
+		}
+		if _, err := os.Stat(path + vocode.PartialExt); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected no partial recording, got: %v", err)
+		}
+	})
+}
+
+func TestDetectAudioFormat(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		head        []byte
+		contentType string
+		exp         vocode.AudioFormat
+	}{
+		{[]byte("RIFF\x00\x00\x00\x00WAVEfmt "), "", vocode.WAVFormat},
+		{[]byte{0xFF, 0xFB, 0x90, 0x00}, "", vocode.MP3Format},
+		{[]byte("OggS\x00\x02"), "audio/wav", vocode.OggFormat},
+		{[]byte("fLaC"), "", vocode.FLACFormat},
+		{[]byte("\x00\x00\x00\x20ftypM4A "), "", vocode.M4AFormat},
+		{[]byte("garbage"), "audio/x-wav; charset=binary", vocode.WAVFormat},
+		{[]byte("garbage"), "application/octet-stream", vocode.UnknownFormat},
+	}
+	for _, tc := range testCases {
+		if got := vocode.DetectAudioFormat(tc.head, tc.contentType); got != tc.exp {
+			t.Fatalf("expected format: %s, got: %s", tc.exp, got)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package redial
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/webhook"
+)
+
+const (
+	// AttemptKey is the call context key which holds the call attempt number.
+	// It lets the engine track the attempts of the calls it has not scheduled
+	// e.g. the calls observed via webhooks after the process restarts.
+	AttemptKey = "redial_attempt"
+	// DefaultSeenTTL is the default time the redialed calls are
+	// remembered for after their redials are due.
+	DefaultSeenTTL = time.Hour
+)
+
+// Redial is the scheduled redial of the call.
+type Redial struct {
+	// Due is the time when the call should be placed.
+	Due time.Time
+	// Attempt is the number of the call attempt.
+	Attempt int
+	// Reason is the reason of the redial.
+	Reason Reason
+	// PrevCallID is the ID of the call which is redialed.
+	PrevCallID string
+	// Req is the request creating the call.
+	Req vocode.CreateCallReq
+}
+
+// Engine applies the redial policy to the ended calls
+// and schedules the redials of the unsuccessful ones.
+// It is safe for concurrent use.
+type Engine struct {
+	policy  Policy
+	window  *window
+	clock   vocode.Clock
+	seenTTL time.Duration
+
+	mu sync.Mutex
+	// seen are the expiry times of the redialed calls.
+	seen    map[string]time.Time
+	sweepAt time.Time
+	pending []Redial
+}
+
+// Options configure the Engine.
+type Options struct {
+	Clock vocode.Clock
+	// SeenTTL is the time the redialed calls are remembered for
+	// after their redials are due so their duplicate observations,
+	// e.g. the late webhook redeliveries, are ignored.
+	SeenTTL time.Duration
+}
+
+// Option is functional engine option.
+type Option func(*Options)
+
+// WithClock sets the engine clock.
+func WithClock(c vocode.Clock) Option {
+	return func(o *Options) {
+		o.Clock = c
+	}
+}
+
+// WithSeenTTL sets the time the redialed calls
+// are remembered for after their redials are due.
+func WithSeenTTL(d time.Duration) Option {
+	return func(o *Options) {
+		o.SeenTTL = d
+	}
+}
+
+// NewEngine creates a new Engine applying the policy and returns it.
+func NewEngine(policy Policy, opts ...Option) (*Engine, error) {
+	options := Options{
+		Clock:   vocode.DefaultClock,
+		SeenTTL: DefaultSeenTTL,
+	}
+	for _, apply := range opts {
+		apply(&options)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	e := &Engine{
+		policy:  policy,
+		clock:   options.Clock,
+		seenTTL: options.SeenTTL,
+		seen:    make(map[string]time.Time),
+	}
+	if policy.Window != nil {
+		w, err := policy.Window.parse()
+		if err != nil {
+			return nil, err
+		}
+		e.window = w
+	}
+	return e, nil
+}
+
+// Observe applies the policy to the call and returns its redial, if any.
+// The call is ignored if it has not ended or if it has already been
+// redialed, so it's safe to observe the same call from both polling
+// and webhooks. The redialed calls are remembered until SeenTTL after
+// their redials are due. The returned redial is also queued; see Due.
+func (e *Engine) Observe(call *vocode.Call) (*Redial, Reason) {
+	if call.Status != vocode.CallEnded && call.Status != vocode.CallError {
+		return nil, ""
+	}
+	reason := Classify(call)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.clock.Now()
+	e.sweep(now)
+	if _, ok := e.seen[call.ID]; ok {
+		return nil, reason
+	}
+
+	// NOTE: the calls which are not redialed end their redial chain
+	// so they are not remembered: observing them again is a no-op.
+	attempt := Attempt(call)
+	if !e.policy.retries(reason) || attempt >= e.policy.MaxAttempts {
+		return nil, reason
+	}
+
+	due := now.Add(e.policy.backoff(attempt))
+	if e.window != nil {
+		due = e.window.next(due)
+	}
+	e.seen[call.ID] = due.Add(e.seenTTL)
+
+	r := Redial{
+		Due:        due,
+		Attempt:    attempt + 1,
+		Reason:     reason,
+		PrevCallID: call.ID,
+		Req:        e.request(call, attempt+1, reason),
+	}
+	e.pending = append(e.pending, r)
+	sort.SliceStable(e.pending, func(i, j int) bool {
+		return e.pending[i].Due.Before(e.pending[j].Due)
+	})
+
+	return &r, reason
+}
+
+// sweep forgets the expired redialed calls.
+// The calls are swept at most once per SeenTTL.
+func (e *Engine) sweep(now time.Time) {
+	if now.Before(e.sweepAt) {
+		return
+	}
+	for id, expiry := range e.seen {
+		if now.After(expiry) {
+			delete(e.seen, id)
+		}
+	}
+	e.sweepAt = now.Add(e.seenTTL)
+}
+
+// HandleEvent observes the calls of the call ended and the call didn't
+// connect webhook events. It can be registered with webhook.Router.
+func (e *Engine) HandleEvent(_ context.Context, ev webhook.Event) error {
+	switch ev.EventType() {
+	case vocode.CallEndedEvent, vocode.CallDidntConnectEvent:
+		e.Observe(ev.EventCall())
+	}
+	return nil
+}
+
+// Due removes the redials which are due at the given time
+// from the queue and returns them ordered by their due time.
+func (e *Engine) Due(now time.Time) []Redial {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	n := 0
+	for n < len(e.pending) && !e.pending[n].Due.After(now) {
+		n++
+	}
+	due := make([]Redial, n)
+	copy(due, e.pending[:n])
+	e.pending = e.pending[n:]
+	return due
+}
+
+// Pending returns the number of the queued redials.
+func (e *Engine) Pending() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return len(e.pending)
+}
+
+// request returns the request redialing the call.
+func (e *Engine) request(call *vocode.Call, attempt int, reason Reason) vocode.CreateCallReq {
+	req := vocode.CreateCallReq{
+		FromNr:          call.FromNumber,
+		ToNr:            call.ToNumber,
+		OnHumanNoAnswer: call.OnNoHumanAnswer,
+		RunDNC:          call.RunDNC,
+		HIPAACompliant:  call.HIPAACompliant,
+		Context:         make(map[string]any, len(call.Context)+1),
+	}
+	if call.Agent != nil {
+		req.Agent = call.Agent.ID
+	}
+	for k, v := range call.Context {
+		req.Context[k] = v
+	}
+	req.Context[AttemptKey] = attempt
+
+	if reason == ReasonVoicemail && e.policy.VoicemailAgent != "" {
+		req.Agent = e.policy.VoicemailAgent
+		// NOTE: the voicemail agent must not hang up on the voicemail
+		req.OnHumanNoAnswer = vocode.ContinueCallOnNoHumanAnswer
+	}
+	return req
+}
+
+// Attempt returns the attempt number of the call stored in its context.
+// It returns 1 if the call context does not contain the attempt number.
+func Attempt(call *vocode.Call) int {
+	switch v := call.Context[AttemptKey].(type) {
+	case int:
+		return max(v, 1)
+	case float64:
+		return max(int(v), 1)
+	}
+	return 1
+}
@@ -0,0 +1,199 @@
+// Package redial schedules the redials of the unsuccessful calls.
+package redial
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// Reason classifies the outcome of the ended call.
+type Reason string
+
+const (
+	// ReasonNoAnswer calls were not answered.
+	ReasonNoAnswer Reason = "no_answer"
+	// ReasonNotConnected calls did not connect.
+	ReasonNotConnected Reason = "not_connected"
+	// ReasonVoicemail calls were not answered by a human.
+	ReasonVoicemail Reason = "voicemail"
+	// ReasonError calls failed with an error.
+	ReasonError Reason = "error"
+	// ReasonDNC calls were answered by someone who asked not to be called.
+	// They are never redialed.
+	ReasonDNC Reason = "dnc"
+	// ReasonCompleted calls were answered by a human.
+	// They are never redialed.
+	ReasonCompleted Reason = "completed"
+)
+
+// DefaultRetryOn are the reasons redialed by default.
+var DefaultRetryOn = []Reason{ReasonNoAnswer, ReasonNotConnected, ReasonVoicemail}
+
+// Classify returns the reason of the call outcome.
+// The do not call result takes precedence over any other outcome.
+func Classify(call *vocode.Call) Reason {
+	switch {
+	case call.DNC:
+		return ReasonDNC
+	case call.Status == vocode.CallError:
+		return ReasonError
+	case call.StageOutcome == vocode.CallStageHumanDisconnect:
+		return ReasonCompleted
+	case call.HumanDetection == vocode.CallNoHumanDetected:
+		return ReasonVoicemail
+	case call.StageOutcome == vocode.CallStageHumanUnAnswer:
+		return ReasonNoAnswer
+	case call.StageOutcome == vocode.CallStageDidNotConnect:
+		return ReasonNotConnected
+	}
+	return ReasonCompleted
+}
+
+// Duration is time.Duration which is JSON encoded as a string e.g. "1h30m".
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Window is the daily time window in which the calls are allowed.
+type Window struct {
+	// Start is the window start time in the 15:04 format.
+	Start string `json:"start"`
+	// End is the window end time in the 15:04 format.
+	// Windows whose end is before their start span midnight.
+	End string `json:"end"`
+	// Location is the IANA time zone of the window e.g. Europe/London.
+	// UTC is used if it's empty.
+	Location string `json:"location,omitempty"`
+}
+
+// Policy declares when and how the calls are redialed.
+type Policy struct {
+	// MaxAttempts is the maximum number of call attempts
+	// per target including the first one.
+	MaxAttempts int `json:"max_attempts"`
+	// Backoff are the delays before the subsequent redials.
+	// The last delay is used for all the remaining redials.
+	Backoff []Duration `json:"backoff"`
+	// RetryOn are the redialed reasons.
+	// DefaultRetryOn is used if it's empty.
+	RetryOn []Reason `json:"retry_on,omitempty"`
+	// VoicemailAgent is the ID of the agent used
+	// to redial the ReasonVoicemail calls.
+	VoicemailAgent string `json:"voicemail_agent,omitempty"`
+	// Window restricts the redials to the daily time window.
+	Window *Window `json:"window,omitempty"`
+}
+
+// backoff returns the delay before the given redial.
+func (p *Policy) backoff(redial int) time.Duration {
+	if len(p.Backoff) == 0 {
+		return 0
+	}
+	if redial > len(p.Backoff) {
+		redial = len(p.Backoff)
+	}
+	return time.Duration(p.Backoff[redial-1])
+}
+
+// retries returns true if the reason is redialed.
+func (p *Policy) retries(reason Reason) bool {
+	if reason == ReasonDNC || reason == ReasonCompleted {
+		return false
+	}
+	retryOn := p.RetryOn
+	if len(retryOn) == 0 {
+		retryOn = DefaultRetryOn
+	}
+	for _, r := range retryOn {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the policy is valid.
+func (p *Policy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("invalid max attempts: %d", p.MaxAttempts)
+	}
+	for _, b := range p.Backoff {
+		if b < 0 {
+			return fmt.Errorf("invalid backoff: %s", time.Duration(b))
+		}
+	}
+	if p.Window != nil {
+		if _, err := p.Window.parse(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type window struct {
+	start, end time.Duration
+	loc        *time.Location
+}
+
+func (w *Window) parse() (*window, error) {
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid window start: %w", err)
+	}
+	end, err := time.Parse("15:04", w.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid window end: %w", err)
+	}
+	loc := time.UTC
+	if w.Location != "" {
+		if loc, err = time.LoadLocation(w.Location); err != nil {
+			return nil, fmt.Errorf("invalid window location: %w", err)
+		}
+	}
+	return &window{
+		start: time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+		end:   time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute,
+		loc:   loc,
+	}, nil
+}
+
+// next returns the earliest time not before t within the window.
+func (w *window) next(t time.Time) time.Time {
+	local := t.In(w.loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, w.loc)
+	offset := local.Sub(day)
+
+	in := false
+	if w.start <= w.end {
+		in = offset >= w.start && offset < w.end
+	} else {
+		in = offset >= w.start || offset < w.end
+	}
+	if in {
+		return t
+	}
+	if offset < w.start {
+		return day.Add(w.start)
+	}
+	return day.AddDate(0, 0, 1).Add(w.start)
+}
@@ -0,0 +1,196 @@
+package redial
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/webhook"
+)
+
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time                       { return c.now }
+func (c fixedClock) After(time.Duration) <-chan time.Time { return nil }
+
+const policyJSON = `{
+	"max_attempts": 3,
+	"backoff": ["10m", "1h"],
+	"voicemail_agent": "vm-agent",
+	"window": {"start": "09:00", "end": "18:00", "location": "UTC"}
+}`
+
+func newEngine(t *testing.T, now time.Time) *Engine {
+	t.Helper()
+	var p Policy
+	if err := json.Unmarshal([]byte(policyJSON), &p); err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewEngine(p, WithClock(fixedClock{now: now}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func endedCall(id string, outcome vocode.CallStageOutcome) *vocode.Call {
+	return &vocode.Call{
+		ID:           id,
+		Status:       vocode.CallEnded,
+		StageOutcome: outcome,
+		FromNumber:   "+15550000",
+		ToNumber:     "+15550001",
+		Agent:        &vocode.Agent{ID: "agent"},
+		Context:      map[string]any{"name": "Alice"},
+	}
+}
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		call *vocode.Call
+		exp  Reason
+	}{
+		{&vocode.Call{StageOutcome: vocode.CallStageHumanDisconnect, DNC: true}, ReasonDNC},
+		{&vocode.Call{StageOutcome: vocode.CallStageHumanDisconnect}, ReasonCompleted},
+		{&vocode.Call{StageOutcome: vocode.CallStageHumanUnAnswer}, ReasonNoAnswer},
+		{&vocode.Call{StageOutcome: vocode.CallStageDidNotConnect}, ReasonNotConnected},
+		{&vocode.Call{StageOutcome: vocode.CallStageBotDisconnect, HumanDetection: vocode.CallNoHumanDetected}, ReasonVoicemail},
+		{&vocode.Call{Status: vocode.CallError}, ReasonError},
+	}
+	for _, tc := range testCases {
+		if got := Classify(tc.call); got != tc.exp {
+			t.Fatalf("expected reason: %s, got: %s", tc.exp, got)
+		}
+	}
+}
+
+func TestEngine(t *testing.T) {
+	t.Parallel()
+	t.Run("redial", func(t *testing.T) {
+		t.Parallel()
+		now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		e := newEngine(t, now)
+
+		r, reason := e.Observe(endedCall("1", vocode.CallStageHumanUnAnswer))
+		if r == nil || reason != ReasonNoAnswer {
+			t.Fatalf("expected redial, got: %v (%s)", r, reason)
+		}
+		if !r.Due.Equal(now.Add(10*time.Minute)) || r.Attempt != 2 {
+			t.Fatalf("unexpected redial: %+v", r)
+		}
+		if r.Req.Agent != "agent" || r.Req.ToNr != "+15550001" || r.Req.Context["name"] != "Alice" || r.Req.Context[AttemptKey] != 2 {
+			t.Fatalf("unexpected redial request: %+v", r.Req)
+		}
+
+		// duplicate observations are ignored
+		if r, _ := e.Observe(endedCall("1", vocode.CallStageHumanUnAnswer)); r != nil {
+			t.Fatalf("unexpected redial: %+v", r)
+		}
+
+		// the attempt number is read from the JSON decoded call context
+		call := endedCall("2", vocode.CallStageDidNotConnect)
+		call.Context[AttemptKey] = float64(2)
+		r, _ = e.Observe(call)
+		if r == nil || r.Attempt != 3 || !r.Due.Equal(now.Add(time.Hour)) {
+			t.Fatalf("unexpected redial: %+v", r)
+		}
+
+		call = endedCall("3", vocode.CallStageDidNotConnect)
+		call.Context[AttemptKey] = float64(3)
+		if r, _ := e.Observe(call); r != nil {
+			t.Fatalf("expected no redial after max attempts, got: %+v", r)
+		}
+
+		if due := e.Due(now.Add(30 * time.Minute)); len(due) != 1 || due[0].PrevCallID != "1" {
+			t.Fatalf("unexpected due redials: %+v", due)
+		}
+		if e.Pending() != 1 {
+			t.Fatalf("expected pending redials: %d, got: %d", 1, e.Pending())
+		}
+	})
+	t.Run("seen_ttl", func(t *testing.T) {
+		t.Parallel()
+		var p Policy
+		if err := json.Unmarshal([]byte(policyJSON), &p); err != nil {
+			t.Fatal(err)
+		}
+		now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		clock := &fixedClock{now: now}
+		e, err := NewEngine(p, WithClock(clock), WithSeenTTL(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the calls which are not redialed are not remembered
+		e.Observe(endedCall("1", vocode.CallStageHumanDisconnect))
+		if r, _ := e.Observe(endedCall("2", vocode.CallStageHumanUnAnswer)); r == nil {
+			t.Fatal("expected redial")
+		}
+		if len(e.seen) != 1 {
+			t.Fatalf("expected seen calls: %d, got: %d", 1, len(e.seen))
+		}
+
+		// the redialed call is remembered until the TTL after its redial is due
+		clock.now = now.Add(time.Hour)
+		if r, _ := e.Observe(endedCall("2", vocode.CallStageHumanUnAnswer)); r != nil {
+			t.Fatalf("unexpected redial: %+v", r)
+		}
+		clock.now = now.Add(10*time.Minute + 2*time.Hour)
+		e.Observe(endedCall("3", vocode.CallStageHumanDisconnect))
+		if len(e.seen) != 0 {
+			t.Fatalf("expected seen calls: %d, got: %d", 0, len(e.seen))
+		}
+	})
+	t.Run("no_redial", func(t *testing.T) {
+		t.Parallel()
+		e := newEngine(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+
+		if r, _ := e.Observe(endedCall("1", vocode.CallStageHumanDisconnect)); r != nil {
+			t.Fatalf("unexpected redial: %+v", r)
+		}
+		call := endedCall("2", vocode.CallStageHumanUnAnswer)
+		call.DNC = true
+		if r, reason := e.Observe(call); r != nil || reason != ReasonDNC {
+			t.Fatalf("unexpected redial: %+v (%s)", r, reason)
+		}
+		call = endedCall("3", "")
+		call.Status = vocode.CallInProgress
+		if r, _ := e.Observe(call); r != nil {
+			t.Fatalf("unexpected redial: %+v", r)
+		}
+	})
+	t.Run("voicemail_window", func(t *testing.T) {
+		t.Parallel()
+		now := time.Date(2024, 5, 1, 17, 55, 0, 0, time.UTC)
+		e := newEngine(t, now)
+
+		call := endedCall("1", vocode.CallStageBotDisconnect)
+		call.HumanDetection = vocode.CallNoHumanDetected
+		r, _ := e.Observe(call)
+		if r == nil || r.Req.Agent != "vm-agent" || r.Req.OnHumanNoAnswer != vocode.ContinueCallOnNoHumanAnswer {
+			t.Fatalf("unexpected redial: %+v", r)
+		}
+		if exp := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC); !r.Due.Equal(exp) {
+			t.Fatalf("expected due: %v, got: %v", exp, r.Due)
+		}
+	})
+	t.Run("webhook", func(t *testing.T) {
+		t.Parallel()
+		e := newEngine(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+		router := webhook.NewRouter()
+		router.OnCallEnded(func(ctx context.Context, ev *webhook.CallEndedEvent) error {
+			return e.HandleEvent(ctx, ev)
+		})
+
+		ev := &webhook.CallEndedEvent{Call: *endedCall("1", vocode.CallStageHumanUnAnswer)}
+		if err := router.Handle(context.Background(), ev); err != nil {
+			t.Fatal(err)
+		}
+		if e.Pending() != 1 {
+			t.Fatalf("expected pending redials: %d, got: %d", 1, e.Pending())
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
 
 	"github.com/milosgajdos/go-vocode/client"
 )
@@ -46,7 +47,18 @@ func NewHTTP(ctx context.Context, method, url string, body io.Reader, opts ...HT
 	return req, nil
 }
 
+// ResponseError is an API error which records
+// the details of the HTTP response it was decoded from.
+type ResponseError interface {
+	error
+	// SetResponse is called with the error response and its body.
+	SetResponse(resp *http.Response, body []byte)
+}
+
 // Do sends the HTTP request req using the client and returns the response.
+// If the response status is not successful, its body is decoded into
+// a new T which is returned as error. If T implements ResponseError
+// it is returned even if the body could not be decoded.
 func Do[T error](client *client.HTTP, req *http.Request) (*http.Response, error) {
 	resp, err := client.Do(req)
 	if err != nil {
@@ -57,14 +69,48 @@ func Do[T error](client *client.HTTP, req *http.Request) (*http.Response, error)
 	}
 	defer resp.Body.Close()
 
-	var apiErr T
-	if jsonErr := json.NewDecoder(resp.Body).Decode(&apiErr); jsonErr != nil {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	apiErr := newError[T]()
+	var jsonErr error
+	if v := reflect.ValueOf(apiErr); v.Kind() == reflect.Pointer && !v.IsNil() {
+		jsonErr = json.Unmarshal(body, apiErr)
+	} else {
+		jsonErr = json.Unmarshal(body, &apiErr)
+	}
+
+	if respErr, ok := any(apiErr).(ResponseError); ok && !isNil(respErr) {
+		respErr.SetResponse(resp, body)
+		return nil, apiErr
+	}
+	if jsonErr != nil {
 		return nil, jsonErr
 	}
 
 	return nil, apiErr
 }
 
+// newError returns a new T. If T is a pointer,
+// it is allocated so it can be decoded into.
+func newError[T error]() T {
+	var apiErr T
+	if t := reflect.TypeOf((*T)(nil)).Elem(); t.Kind() == reflect.Pointer {
+		apiErr = reflect.New(t.Elem()).Interface().(T)
+	}
+	return apiErr
+}
+
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
 // PageParams contain key-value pairs with request paging parameters
 type PageParams map[string]string
 
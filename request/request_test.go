@@ -3,10 +3,14 @@ package request
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+
+	"github.com/milosgajdos/go-vocode/client"
 )
 
 func TestNewHTTPRequest(t *testing.T) {
@@ -84,3 +88,51 @@ func TestHTTPReqOption(t *testing.T) {
 		}
 	})
 }
+
+type testError struct {
+	Detail     string `json:"detail"`
+	StatusCode int    `json:"-"`
+}
+
+func (e *testError) Error() string { return e.Detail }
+
+func (e *testError) SetResponse(resp *http.Response, _ []byte) { e.StatusCode = resp.StatusCode }
+
+func TestDo(t *testing.T) {
+	t.Parallel()
+	t.Run("json_error", func(t *testing.T) {
+		t.Parallel()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"detail":"missing"}`))
+		}))
+		defer ts.Close()
+
+		req, err := NewHTTP(context.TODO(), http.MethodGet, ts.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Do[*testError](client.NewHTTP(), req)
+		var e *testError
+		if !errors.As(err, &e) || e.Detail != "missing" || e.StatusCode != http.StatusNotFound {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	t.Run("non_json_error", func(t *testing.T) {
+		t.Parallel()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "oops", http.StatusInternalServerError)
+		}))
+		defer ts.Close()
+
+		req, err := NewHTTP(context.TODO(), http.MethodGet, ts.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Do[*testError](client.NewHTTP(), req)
+		var e *testError
+		if !errors.As(err, &e) || e.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
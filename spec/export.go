@@ -0,0 +1,294 @@
+package spec
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// Export returns the Spec of all the live account resources.
+//
+// The resources are named after their names, types or the agents
+// which reference them, and the IDs referencing the other resources
+// are replaced by their names. The secrets are replaced by placeholders
+// named VOCODE_<KIND>_<NAME>_<FIELD>, e.g. the ElevenLabs API key of
+// the voice "support" is ${VOCODE_VOICE_SUPPORT_API_KEY}.
+//
+// The IDs of the exported resources are recorded in the State
+// so that the exported Spec can be planned against the account.
+func Export(ctx context.Context, c *vocode.Client, state *State) (*Spec, error) {
+	conns, err := list(c.AllAccountConns(ctx, nil))
+	if err != nil {
+		return nil, fmt.Errorf("list account connections: %w", err)
+	}
+	vectorDBs, err := list(c.AllVectorDBs(ctx, nil))
+	if err != nil {
+		return nil, fmt.Errorf("list vector databases: %w", err)
+	}
+	prompts, err := list(c.AllPrompts(ctx, nil))
+	if err != nil {
+		return nil, fmt.Errorf("list prompts: %w", err)
+	}
+	voices, err := list(c.AllVoices(ctx, nil))
+	if err != nil {
+		return nil, fmt.Errorf("list voices: %w", err)
+	}
+	actions, err := list(c.AllActions(ctx, nil))
+	if err != nil {
+		return nil, fmt.Errorf("list actions: %w", err)
+	}
+	webhooks, err := list(c.AllWebhooks(ctx, nil))
+	if err != nil {
+		return nil, fmt.Errorf("list webhooks: %w", err)
+	}
+	agents, err := list(c.AllAgents(ctx, nil))
+	if err != nil {
+		return nil, fmt.Errorf("list agents: %w", err)
+	}
+	numbers, err := list(c.AllNumbers(ctx, nil))
+	if err != nil {
+		return nil, fmt.Errorf("list numbers: %w", err)
+	}
+
+	n := newNamer()
+
+	// NOTE: agents are named first so the resources
+	// they reference can be named after them.
+	for _, a := range agents {
+		n.name(AgentKind, a.ID, a.Name)
+	}
+	for _, a := range agents {
+		name := n.names[AgentKind][a.ID]
+		if a.Prompt != nil {
+			n.name(PromptKind, a.Prompt.ID, name)
+		}
+		if a.Webhook != nil {
+			n.name(WebhookKind, a.Webhook.ID, name)
+		}
+	}
+	for _, a := range conns {
+		n.name(AccountConnKind, a.ID, strings.TrimPrefix(string(a.Type), "account_connection_"))
+	}
+	for _, v := range vectorDBs {
+		n.name(VectorDBKind, v.ID, v.Index)
+	}
+	for _, p := range prompts {
+		n.name(PromptKind, p.ID, string(PromptKind))
+	}
+	for _, v := range voices {
+		n.name(VoiceKind, v.ID, strings.TrimPrefix(string(v.Type), "voice_"))
+	}
+	for _, a := range actions {
+		n.name(ActionKind, a.ID, strings.TrimPrefix(string(a.Type), "action_"))
+	}
+	for _, w := range webhooks {
+		n.name(WebhookKind, w.ID, string(WebhookKind))
+	}
+
+	s := &Spec{
+		AccountConns: make(map[string]vocode.AccountConnReq, len(conns)),
+		VectorDBs:    make(map[string]vocode.VectorDBReq, len(vectorDBs)),
+		Prompts:      make(map[string]vocode.PromptReq, len(prompts)),
+		Voices:       make(map[string]vocode.VoiceReq, len(voices)),
+		Actions:      make(map[string]vocode.ActionReq, len(actions)),
+		Webhooks:     make(map[string]vocode.WebhookReq, len(webhooks)),
+		Agents:       make(map[string]vocode.AgentReq, len(agents)),
+		Numbers:      make(map[string]Number, len(numbers)),
+	}
+
+	for i := range conns {
+		name := n.names[AccountConnKind][conns[i].ID]
+		req := AccountConnReq(&conns[i])
+		if a := req.TwilioAccount; a != nil && a.Creds != nil && a.Creds.AuthToken != "" {
+			a.Creds.AuthToken = placeholder(AccountConnKind, name, "auth_token")
+		}
+		if a := req.OpenAIAccount; a != nil && a.Creds != nil && a.Creds.APIKey != "" {
+			a.Creds.APIKey = placeholder(AccountConnKind, name, "api_key")
+		}
+		s.AccountConns[name] = req
+		state.Set(AccountConnKind, name, conns[i].ID)
+	}
+	for i := range vectorDBs {
+		name := n.names[VectorDBKind][vectorDBs[i].ID]
+		req := VectorDBReq(&vectorDBs[i])
+		if req.APIKey != "" {
+			req.APIKey = placeholder(VectorDBKind, name, "api_key")
+		}
+		s.VectorDBs[name] = req
+		state.Set(VectorDBKind, name, vectorDBs[i].ID)
+	}
+	for i := range prompts {
+		name := n.names[PromptKind][prompts[i].ID]
+		s.Prompts[name] = PromptReq(&prompts[i])
+		state.Set(PromptKind, name, prompts[i].ID)
+	}
+	for i := range voices {
+		name := n.names[VoiceKind][voices[i].ID]
+		req := VoiceReq(&voices[i])
+		if v := req.ElevenLabsVoice; v != nil && v.APIKey != "" {
+			v.APIKey = placeholder(VoiceKind, name, "api_key")
+		}
+		if v := req.PlayHtVoice; v != nil && v.APIKey != "" {
+			v.APIKey = placeholder(VoiceKind, name, "api_key")
+		}
+		s.Voices[name] = req
+		state.Set(VoiceKind, name, voices[i].ID)
+	}
+	for i := range actions {
+		name := n.names[ActionKind][actions[i].ID]
+		s.Actions[name] = ActionReq(&actions[i])
+		state.Set(ActionKind, name, actions[i].ID)
+	}
+	for i := range webhooks {
+		name := n.names[WebhookKind][webhooks[i].ID]
+		s.Webhooks[name] = WebhookReq(&webhooks[i])
+		state.Set(WebhookKind, name, webhooks[i].ID)
+	}
+	for i := range agents {
+		name := n.names[AgentKind][agents[i].ID]
+		req := AgentReq(&agents[i])
+		if err := n.refs(&req); err != nil {
+			return nil, fmt.Errorf("agent %q: %w", name, err)
+		}
+		if a := req.OpenAIAccount; a != nil && a.Creds != nil && a.Creds.APIKey != "" {
+			a.Creds.APIKey = placeholder(AgentKind, name, "openai_api_key")
+		}
+		s.Agents[name] = req
+		state.Set(AgentKind, name, agents[i].ID)
+	}
+	for i := range numbers {
+		number := NumberSpec(&numbers[i])
+		if number.InboundAgent != "" {
+			name, err := n.ref(AgentKind, number.InboundAgent)
+			if err != nil {
+				return nil, fmt.Errorf("number %q: %w", numbers[i].Number, err)
+			}
+			number.InboundAgent = name
+		}
+		s.Numbers[numbers[i].Number] = number
+		state.Set(NumberKind, numbers[i].Number, numbers[i].ID)
+	}
+
+	return s, nil
+}
+
+// list returns all the items of the pager.
+func list[T any](p *vocode.Pager[T]) ([]T, error) {
+	var items []T
+	for p.Next() {
+		items = append(items, p.Item())
+	}
+	return items, p.Err()
+}
+
+// placeholder returns the placeholder of the resource secret field.
+func placeholder(kind Kind, name, field string) string {
+	return "${" + envName("VOCODE_"+string(kind)+"_"+name+"_"+field) + "}"
+}
+
+// envName returns s as the environment variable name.
+func envName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return '_'
+	}, s)
+}
+
+// namer assigns the unique symbolic names to the resource IDs.
+type namer struct {
+	names map[Kind]map[string]string
+	taken map[Kind]map[string]bool
+}
+
+func newNamer() *namer {
+	n := &namer{
+		names: make(map[Kind]map[string]string),
+		taken: make(map[Kind]map[string]bool),
+	}
+	for _, kind := range Kinds {
+		n.names[kind] = make(map[string]string)
+		n.taken[kind] = make(map[string]bool)
+	}
+	return n
+}
+
+// name names the resource after base unless it's already named.
+// The name is suffixed with a number if base is already taken.
+func (n *namer) name(kind Kind, id, base string) {
+	if _, ok := n.names[kind][id]; ok {
+		return
+	}
+	base = slug(base)
+	if base == "" {
+		base = slug(string(kind))
+	}
+	name := base
+	for i := 2; n.taken[kind][name]; i++ {
+		name = base + "-" + strconv.Itoa(i)
+	}
+	n.names[kind][id] = name
+	n.taken[kind][name] = true
+}
+
+// ref returns the name of the referenced resource.
+func (n *namer) ref(kind Kind, id string) (string, error) {
+	name, ok := n.names[kind][id]
+	if !ok {
+		return "", fmt.Errorf("unknown %s %s", kind, id)
+	}
+	return name, nil
+}
+
+// refs replaces the IDs referenced by the agent with their names.
+func (n *namer) refs(a *vocode.AgentReq) error {
+	var err error
+	for _, r := range []struct {
+		kind Kind
+		id   *string
+	}{
+		{PromptKind, &a.Prompt},
+		{VoiceKind, &a.Voice},
+		{WebhookKind, &a.Webhook},
+		{VectorDBKind, &a.VectorDB},
+	} {
+		if *r.id == "" {
+			continue
+		}
+		if *r.id, err = n.ref(r.kind, *r.id); err != nil {
+			return err
+		}
+	}
+	for i, id := range a.Actions {
+		if a.Actions[i], err = n.ref(ActionKind, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// slug returns the lower case s with all the runs
+// of non-alphanumeric characters replaced by a dash.
+func slug(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return sb.String()
+}
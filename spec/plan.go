@@ -0,0 +1,284 @@
+package spec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// Op is the plan operation.
+type Op string
+
+const (
+	// OpNoop resources are up to date.
+	OpNoop Op = "no-op"
+	// OpCreate resources do not exist yet.
+	OpCreate Op = "create"
+	// OpUpdate resources differ from the Spec.
+	OpUpdate Op = "update"
+)
+
+// Change is the planned change of the resource.
+type Change struct {
+	Kind Kind
+	Name string
+	Op   Op
+	// ID is the ID of the live resource.
+	// It is empty for the created resources.
+	ID string
+	// Fields are the fields changed by the update.
+	Fields []string
+
+	desired object
+	live    object
+}
+
+// Plan is the list of changes which reconcile
+// the live resources with the Spec.
+type Plan struct {
+	// Changes are ordered so that all the resources
+	// are changed after the resources they reference.
+	Changes []Change
+}
+
+// Plan compares the Spec with the live resources recorded in the
+// State and returns the plan reconciling them. The resources which
+// are not recorded in the State, or which no longer exist, are created.
+// The phone numbers are looked up by their number and must exist.
+func (s *Spec) Plan(ctx context.Context, c *vocode.Client, state *State) (*Plan, error) {
+	plan := &Plan{}
+	creates := make(map[Kind]map[string]bool)
+
+	// lookup returns the ID of the referenced resource if it's known.
+	lookup := func(kind Kind, name string) (string, bool) {
+		if creates[kind][name] {
+			return "", false
+		}
+		return state.ID(kind, name)
+	}
+
+	for _, kind := range Kinds {
+		creates[kind] = make(map[string]bool)
+		for _, name := range s.Names(kind) {
+			desired, err := s.object(kind, name)
+			if err != nil {
+				return nil, fmt.Errorf("%s %q: %w", kind, name, err)
+			}
+
+			change := Change{
+				Kind:    kind,
+				Name:    name,
+				desired: desired,
+			}
+
+			id, ok := state.ID(kind, name)
+			if ok || kind == NumberKind {
+				liveID, live, err := ops[kind].get(ctx, c, name, id)
+				switch {
+				case err == nil:
+					change.ID, change.live = liveID, live
+				case errors.Is(err, vocode.ErrNotFound) && kind != NumberKind:
+					// NOTE: the resource has been deleted so it's recreated
+				default:
+					return nil, fmt.Errorf("%s %q: %w", kind, name, err)
+				}
+			}
+
+			if change.live == nil {
+				change.Op = OpCreate
+				creates[kind][name] = true
+				plan.Changes = append(plan.Changes, change)
+				continue
+			}
+
+			resolved, unknown := resolve(kind, desired, lookup)
+			change.Fields = diff(resolved, change.live, unknown)
+			change.Op = OpNoop
+			if len(change.Fields) > 0 {
+				change.Op = OpUpdate
+			}
+			plan.Changes = append(plan.Changes, change)
+		}
+	}
+
+	return plan, nil
+}
+
+// object returns the JSON object of the resource
+// with only the fields set in the Spec.
+func (s *Spec) object(kind Kind, name string) (object, error) {
+	v, _ := s.resource(kind, name)
+	obj, err := toObject(v)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, ok := s.fields[kind][name]
+	if !ok {
+		return obj, nil
+	}
+	set := make(object, len(fields))
+	for _, f := range fields {
+		if v, ok := obj[f]; ok {
+			set[f] = v
+		}
+	}
+	// NOTE: the type is always needed to decode the typed resources
+	if kind == VoiceKind || kind == AccountConnKind {
+		set["type"] = obj["type"]
+	}
+	return set, nil
+}
+
+// HasChanges returns true if the plan creates or updates any resources.
+func (p *Plan) HasChanges() bool {
+	for _, c := range p.Changes {
+		if c.Op != OpNoop {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the human readable plan.
+func (p *Plan) String() string {
+	var sb strings.Builder
+	creates, updates := 0, 0
+	for _, c := range p.Changes {
+		switch c.Op {
+		case OpCreate:
+			creates++
+			fmt.Fprintf(&sb, "+ %s.%s\n", c.Kind, c.Name)
+		case OpUpdate:
+			updates++
+			fmt.Fprintf(&sb, "~ %s.%s (%s): %s\n", c.Kind, c.Name, c.ID, strings.Join(c.Fields, ", "))
+		}
+	}
+	fmt.Fprintf(&sb, "Plan: %d to create, %d to update.", creates, updates)
+	return sb.String()
+}
+
+// Apply applies the plan changes in their order. The IDs of the
+// resources are recorded in the State which is saved after every
+// change so that the interrupted Apply can be planned and applied again.
+func (p *Plan) Apply(ctx context.Context, c *vocode.Client, state *State) error {
+	for _, change := range p.Changes {
+		if err := p.apply(ctx, c, state, change); err != nil {
+			return fmt.Errorf("%s %s %q: %w", change.Op, change.Kind, change.Name, err)
+		}
+		if err := state.Save(); err != nil {
+			return fmt.Errorf("save state: %w", err)
+		}
+	}
+	return nil
+}
+
+func (p *Plan) apply(ctx context.Context, c *vocode.Client, state *State, change Change) error {
+	obj, unknown := resolve(change.Kind, change.desired, state.ID)
+	if len(unknown) > 0 {
+		return fmt.Errorf("unresolved references: %s", strings.Join(unknown, ", "))
+	}
+
+	switch change.Op {
+	case OpCreate:
+		id, err := ops[change.Kind].create(ctx, c, obj)
+		if err != nil {
+			return err
+		}
+		state.Set(change.Kind, change.Name, id)
+	case OpUpdate:
+		merged := make(object, len(change.live)+len(obj))
+		for k, v := range change.live {
+			merged[k] = v
+		}
+		for k, v := range obj {
+			merged[k] = v
+		}
+		if err := ops[change.Kind].update(ctx, c, change.Name, change.ID, merged); err != nil {
+			return err
+		}
+		state.Set(change.Kind, change.Name, change.ID)
+	default:
+		state.Set(change.Kind, change.Name, change.ID)
+	}
+	return nil
+}
+
+// resolve returns a copy of the object with the symbolic references replaced
+// by the IDs returned by lookup. It returns the fields which are unknown.
+func resolve(kind Kind, obj object, lookup func(Kind, string) (string, bool)) (object, []string) {
+	resolved := make(object, len(obj))
+	for k, v := range obj {
+		resolved[k] = v
+	}
+
+	var unknown []string
+	for _, r := range refs[kind] {
+		v, ok := obj[r.field]
+		if !ok || v == nil || v == "" {
+			continue
+		}
+		if !r.list {
+			id, ok := lookup(r.kind, v.(string))
+			if !ok {
+				unknown = append(unknown, r.field)
+			}
+			resolved[r.field] = id
+			continue
+		}
+		names, _ := v.([]any)
+		ids := make([]any, 0, len(names))
+		for _, name := range names {
+			id, ok := lookup(r.kind, name.(string))
+			if !ok {
+				unknown = append(unknown, r.field)
+				break
+			}
+			ids = append(ids, id)
+		}
+		resolved[r.field] = ids
+	}
+	return resolved, unknown
+}
+
+// diff returns the sorted desired fields which differ from the live ones.
+func diff(desired, live object, unknown []string) []string {
+	fields := append([]string(nil), unknown...)
+	for k, v := range desired {
+		if !equal(v, live[k]) {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+	return slices.Compact(fields)
+}
+
+// equal returns true if a and b are deeply equal.
+// The empty values are equal to nil.
+func equal(a, b any) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func normalize(v any) any {
+	switch v := v.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+	case []any:
+		if len(v) == 0 {
+			return nil
+		}
+	case map[string]any:
+		if len(v) == 0 {
+			return nil
+		}
+	}
+	return v
+}
@@ -0,0 +1,411 @@
+package spec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// object is the JSON object of the resource request.
+type object map[string]any
+
+// toObject returns the JSON object of v.
+func toObject(v any) (object, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var obj object
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// fromObject decodes the JSON object into a new T.
+func fromObject[T any](obj object) (*T, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	v := new(T)
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ref is the field referencing other resource.
+type ref struct {
+	field string
+	kind  Kind
+	list  bool
+}
+
+// refs are the reference fields of the resources.
+var refs = map[Kind][]ref{
+	AgentKind: {
+		{field: "prompt", kind: PromptKind},
+		{field: "voice", kind: VoiceKind},
+		{field: "actions", kind: ActionKind, list: true},
+		{field: "webhook", kind: WebhookKind},
+		{field: "vector_database", kind: VectorDBKind},
+	},
+	NumberKind: {
+		{field: "inbound_agent", kind: AgentKind},
+	},
+}
+
+// resourceOps reads and writes the live resources of the kind.
+type resourceOps struct {
+	// get returns the ID and the request object of the live resource.
+	// The phone numbers are fetched by their name, all other resources by their ID.
+	get func(ctx context.Context, c *vocode.Client, name, id string) (string, object, error)
+	// create creates the resource and returns its ID.
+	create func(ctx context.Context, c *vocode.Client, obj object) (string, error)
+	// update updates the resource.
+	update func(ctx context.Context, c *vocode.Client, name, id string, obj object) error
+}
+
+var ops = map[Kind]resourceOps{
+	AccountConnKind: {
+		get: func(ctx context.Context, c *vocode.Client, _, id string) (string, object, error) {
+			a, err := c.GetAccountConn(ctx, id)
+			if err != nil {
+				return "", nil, err
+			}
+			obj, err := toObject(AccountConnReq(a))
+			return a.ID, obj, err
+		},
+		create: func(ctx context.Context, c *vocode.Client, obj object) (string, error) {
+			req, err := fromObject[vocode.AccountConnReq](obj)
+			if err != nil {
+				return "", err
+			}
+			a, err := c.CreateAccountConn(ctx, &vocode.CreateAccountConnReq{AccountConnReq: *req})
+			if err != nil {
+				return "", err
+			}
+			return a.ID, nil
+		},
+		update: func(ctx context.Context, c *vocode.Client, _, id string, obj object) error {
+			req, err := fromObject[vocode.AccountConnReq](obj)
+			if err != nil {
+				return err
+			}
+			_, err = c.UpdateAccountConn(ctx, id, &vocode.UpdateAccountConnReq{AccountConnReq: *req})
+			return err
+		},
+	},
+	VectorDBKind: {
+		get: func(ctx context.Context, c *vocode.Client, _, id string) (string, object, error) {
+			v, err := c.GetVectorDB(ctx, id)
+			if err != nil {
+				return "", nil, err
+			}
+			obj, err := toObject(VectorDBReq(v))
+			return v.ID, obj, err
+		},
+		create: func(ctx context.Context, c *vocode.Client, obj object) (string, error) {
+			req, err := fromObject[vocode.VectorDBReq](obj)
+			if err != nil {
+				return "", err
+			}
+			v, err := c.CreateVectorDB(ctx, &vocode.CreateVectorDBReq{VectorDBReq: *req})
+			if err != nil {
+				return "", err
+			}
+			return v.ID, nil
+		},
+		update: func(ctx context.Context, c *vocode.Client, _, id string, obj object) error {
+			req, err := fromObject[vocode.VectorDBReq](obj)
+			if err != nil {
+				return err
+			}
+			_, err = c.UpdateVectorDB(ctx, id, &vocode.UpdateVectorDBReq{VectorDBReq: *req})
+			return err
+		},
+	},
+	PromptKind: {
+		get: func(ctx context.Context, c *vocode.Client, _, id string) (string, object, error) {
+			p, err := c.GetPrompt(ctx, id)
+			if err != nil {
+				return "", nil, err
+			}
+			obj, err := toObject(PromptReq(p))
+			return p.ID, obj, err
+		},
+		create: func(ctx context.Context, c *vocode.Client, obj object) (string, error) {
+			req, err := fromObject[vocode.PromptReq](obj)
+			if err != nil {
+				return "", err
+			}
+			p, err := c.CreatePrompt(ctx, &vocode.CreatePromptReq{PromptReq: *req})
+			if err != nil {
+				return "", err
+			}
+			return p.ID, nil
+		},
+		update: func(ctx context.Context, c *vocode.Client, _, id string, obj object) error {
+			req, err := fromObject[vocode.PromptReq](obj)
+			if err != nil {
+				return err
+			}
+			_, err = c.UpdatePrompt(ctx, id, &vocode.UpdatePromptReq{PromptReq: *req})
+			return err
+		},
+	},
+	VoiceKind: {
+		get: func(ctx context.Context, c *vocode.Client, _, id string) (string, object, error) {
+			v, err := c.GetVoice(ctx, id)
+			if err != nil {
+				return "", nil, err
+			}
+			obj, err := toObject(VoiceReq(v))
+			return v.ID, obj, err
+		},
+		create: func(ctx context.Context, c *vocode.Client, obj object) (string, error) {
+			req, err := fromObject[vocode.VoiceReq](obj)
+			if err != nil {
+				return "", err
+			}
+			v, err := c.CreateVoice(ctx, &vocode.CreateVoiceReq{VoiceReq: *req})
+			if err != nil {
+				return "", err
+			}
+			return v.ID, nil
+		},
+		update: func(ctx context.Context, c *vocode.Client, _, id string, obj object) error {
+			req, err := fromObject[vocode.VoiceReq](obj)
+			if err != nil {
+				return err
+			}
+			_, err = c.UpdateVoice(ctx, id, &vocode.UpdateVoiceReq{VoiceReq: *req})
+			return err
+		},
+	},
+	ActionKind: {
+		get: func(ctx context.Context, c *vocode.Client, _, id string) (string, object, error) {
+			a, err := c.GetAction(ctx, id)
+			if err != nil {
+				return "", nil, err
+			}
+			obj, err := toObject(ActionReq(a))
+			return a.ID, obj, err
+		},
+		create: func(ctx context.Context, c *vocode.Client, obj object) (string, error) {
+			req, err := fromObject[vocode.ActionReq](obj)
+			if err != nil {
+				return "", err
+			}
+			a, err := c.CreateAction(ctx, &vocode.CreateActionReq{ActionReq: *req})
+			if err != nil {
+				return "", err
+			}
+			return a.ID, nil
+		},
+		update: func(ctx context.Context, c *vocode.Client, _, id string, obj object) error {
+			req, err := fromObject[vocode.ActionReq](obj)
+			if err != nil {
+				return err
+			}
+			_, err = c.UpdateAction(ctx, id, &vocode.UpdateActionReq{ActionReq: *req})
+			return err
+		},
+	},
+	WebhookKind: {
+		get: func(ctx context.Context, c *vocode.Client, _, id string) (string, object, error) {
+			w, err := c.GetWebhook(ctx, id)
+			if err != nil {
+				return "", nil, err
+			}
+			obj, err := toObject(WebhookReq(w))
+			return w.ID, obj, err
+		},
+		create: func(ctx context.Context, c *vocode.Client, obj object) (string, error) {
+			req, err := fromObject[vocode.WebhookReq](obj)
+			if err != nil {
+				return "", err
+			}
+			w, err := c.CreateWebhook(ctx, &vocode.CreateWebhookReq{WebhookReq: *req})
+			if err != nil {
+				return "", err
+			}
+			return w.ID, nil
+		},
+		update: func(ctx context.Context, c *vocode.Client, _, id string, obj object) error {
+			req, err := fromObject[vocode.WebhookReq](obj)
+			if err != nil {
+				return err
+			}
+			_, err = c.UpdateWebhook(ctx, id, &vocode.UpdateWebhookReq{WebhookReq: *req})
+			return err
+		},
+	},
+	AgentKind: {
+		get: func(ctx context.Context, c *vocode.Client, _, id string) (string, object, error) {
+			a, err := c.GetAgent(ctx, id)
+			if err != nil {
+				return "", nil, err
+			}
+			obj, err := toObject(AgentReq(a))
+			return a.ID, obj, err
+		},
+		create: func(ctx context.Context, c *vocode.Client, obj object) (string, error) {
+			req, err := fromObject[vocode.AgentReq](obj)
+			if err != nil {
+				return "", err
+			}
+			a, err := c.CreateAgent(ctx, &vocode.CreateAgentReq{AgentReq: *req})
+			if err != nil {
+				return "", err
+			}
+			return a.ID, nil
+		},
+		update: func(ctx context.Context, c *vocode.Client, _, id string, obj object) error {
+			req, err := fromObject[vocode.AgentReq](obj)
+			if err != nil {
+				return err
+			}
+			_, err = c.UpdateAgent(ctx, id, &vocode.UpdateAgentReq{AgentReq: *req})
+			return err
+		},
+	},
+	NumberKind: {
+		get: func(ctx context.Context, c *vocode.Client, name, _ string) (string, object, error) {
+			n, err := c.GetNumber(ctx, name)
+			if err != nil {
+				return "", nil, err
+			}
+			obj, err := toObject(NumberSpec(n))
+			return n.ID, obj, err
+		},
+		create: func(context.Context, *vocode.Client, object) (string, error) {
+			return "", fmt.Errorf("phone numbers must be bought before they're managed")
+		},
+		update: func(ctx context.Context, c *vocode.Client, name, _ string, obj object) error {
+			req, err := fromObject[vocode.UpdateNumberReq](obj)
+			if err != nil {
+				return err
+			}
+			_, err = c.UpdateNumber(ctx, name, req)
+			return err
+		},
+	},
+}
+
+// AccountConnReq returns the request which recreates the live account connection.
+func AccountConnReq(a *vocode.AccountConn) vocode.AccountConnReq {
+	return vocode.AccountConnReq{
+		Type:          a.Type,
+		TwilioAccount: a.TwilioAccount,
+		OpenAIAccount: a.OpenAIAccount,
+	}
+}
+
+// VectorDBReq returns the request which recreates the live vector database.
+func VectorDBReq(v *vocode.VectorDB) vocode.VectorDBReq {
+	return vocode.VectorDBReq{
+		Type:   v.Type,
+		Index:  v.Index,
+		APIKey: v.APIKey,
+		APIEnv: v.APIEnv,
+	}
+}
+
+// PromptReq returns the request which recreates the live prompt.
+func PromptReq(p *vocode.Prompt) vocode.PromptReq {
+	req := vocode.PromptReq{
+		Content:     p.Content,
+		Fields:      p.Fields,
+		CtxEndpoint: p.CtxEndpoint,
+	}
+	if p.Template != nil {
+		req.Template = p.Template.ID
+	}
+	return req
+}
+
+// VoiceReq returns the request which recreates the live voice.
+func VoiceReq(v *vocode.Voice) vocode.VoiceReq {
+	return vocode.VoiceReq{
+		Type:            v.Type,
+		AzureVoice:      v.AzureVoice,
+		RimeVoice:       v.RimeVoice,
+		ElevenLabsVoice: v.ElevenLabsVoice,
+		PlayHtVoice:     v.PlayHtVoice,
+	}
+}
+
+// ActionReq returns the request which recreates the live action.
+func ActionReq(a *vocode.Action) vocode.ActionReq {
+	return vocode.ActionReq{
+		Type:    a.Type,
+		Trigger: a.Trigger,
+		Config:  a.Config,
+	}
+}
+
+// WebhookReq returns the request which recreates the live webhook.
+func WebhookReq(w *vocode.Webhook) vocode.WebhookReq {
+	return vocode.WebhookReq{
+		Subs:   w.Subs,
+		URL:    w.URL,
+		Method: w.Method,
+	}
+}
+
+// AgentReq returns the request which recreates the live agent.
+// The agent references the other resources by their IDs.
+func AgentReq(a *vocode.Agent) vocode.AgentReq {
+	req := vocode.AgentReq{
+		Name:                     a.Name,
+		Language:                 a.Language,
+		InitMsg:                  a.InitMsg,
+		InterruptSense:           a.InterruptSense,
+		CtxEndpint:               a.CtxEndpint,
+		NoiseSuppression:         a.NoiseSuppression,
+		EndpointSense:            a.EndpointSense,
+		IVRNavMode:               a.IVRNavMode,
+		Speed:                    a.Speed,
+		InitMsgDelay:             a.InitMsgDelay,
+		AsktIfHumanPresentOnIdle: a.AsktIfHumanPresentOnIdle,
+		RunDNCDetection:          a.RunDNCDetection,
+		LLMTemperature:           a.LLMTemperature,
+	}
+	if a.Prompt != nil {
+		req.Prompt = a.Prompt.ID
+	}
+	if a.Voice != nil {
+		req.Voice = a.Voice.ID
+	}
+	for _, action := range a.Actions {
+		req.Actions = append(req.Actions, action.ID)
+	}
+	if a.Webhook != nil {
+		req.Webhook = a.Webhook.ID
+	}
+	if a.VectorDB != nil {
+		req.VectorDB = a.VectorDB.ID
+	}
+	if a.OpenAIAccount != nil {
+		req.OpenAIAccount = a.OpenAIAccount.OpenAIAccount
+	}
+	return req
+}
+
+// NumberSpec returns the spec of the live phone number.
+// The number references its inbound agent by the agent ID.
+func NumberSpec(n *vocode.Number) Number {
+	spec := Number{
+		Label:        n.Label,
+		OutboundOnly: n.OutboundOnly,
+		ExampleCtx:   n.ExampleCtx,
+	}
+	if n.InboundAgent != nil {
+		spec.InboundAgent = n.InboundAgent.ID
+	}
+	return spec
+}
@@ -0,0 +1,337 @@
+// Package spec manages Vocode account resources declaratively.
+//
+// The Spec describes the account connections, vector databases, prompts,
+// voices, actions, webhooks, agents and phone numbers of the account.
+// The resources are keyed by their symbolic names which are used to
+// reference them from other resources e.g. the agent prompt is the name
+// of the prompt in the Spec.
+// The phone numbers are keyed by the phone number as they can't be
+// created declaratively: they must be bought before they're managed.
+//
+//	{
+//	  "prompts": {"support": {"content": "You are a support agent."}},
+//	  "voices": {"rime": {"type": "voice_rime", "speaker": "young_male"}},
+//	  "agents": {"support": {"name": "Support", "prompt": "support", "voice": "rime"}},
+//	  "numbers": {"+14155550100": {"label": "support", "inbound_agent": "support"}}
+//	}
+//
+// Plan compares the Spec with the live resources recorded in the State and
+// returns the changes Apply makes to reconcile them. Only the fields set in
+// the Spec are managed: all the other fields keep their live values.
+//
+// The string values of the form ${NAME} are placeholders which are replaced
+// by the value of the NAME environment variable when the Spec is decoded.
+// Export uses them in place of the secrets of the live resources.
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// Kind is the kind of the managed resource.
+type Kind string
+
+const (
+	AccountConnKind Kind = "account_connection"
+	VectorDBKind    Kind = "vector_database"
+	PromptKind      Kind = "prompt"
+	VoiceKind       Kind = "voice"
+	ActionKind      Kind = "action"
+	WebhookKind     Kind = "webhook"
+	AgentKind       Kind = "agent"
+	NumberKind      Kind = "number"
+)
+
+// Kinds are all the resource kinds in the dependency order.
+var Kinds = []Kind{AccountConnKind, VectorDBKind, PromptKind, VoiceKind, ActionKind, WebhookKind, AgentKind, NumberKind}
+
+// Number is the phone number spec.
+type Number struct {
+	Label        string         `json:"label"`
+	OutboundOnly bool           `json:"outbound_only"`
+	InboundAgent string         `json:"inbound_agent,omitempty"`
+	ExampleCtx   map[string]any `json:"example_context,omitempty"`
+}
+
+// Spec describes the account resources.
+type Spec struct {
+	AccountConns map[string]vocode.AccountConnReq `json:"account_connections,omitempty"`
+	VectorDBs    map[string]vocode.VectorDBReq    `json:"vector_databases,omitempty"`
+	Prompts      map[string]vocode.PromptReq      `json:"prompts,omitempty"`
+	Voices       map[string]vocode.VoiceReq       `json:"voices,omitempty"`
+	Actions      map[string]vocode.ActionReq      `json:"actions,omitempty"`
+	Webhooks     map[string]vocode.WebhookReq     `json:"webhooks,omitempty"`
+	Agents       map[string]vocode.AgentReq       `json:"agents,omitempty"`
+	Numbers      map[string]Number                `json:"numbers,omitempty"`
+
+	// fields are the fields set for the resources
+	// when the spec was decoded from JSON.
+	fields map[Kind]map[string][]string
+}
+
+// placeholderRe matches the placeholder and captures its variable name.
+var placeholderRe = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+
+// Options configure decoding of the Spec.
+type Options struct {
+	// LookupEnv returns the value of the placeholder variable.
+	LookupEnv func(name string) (string, bool)
+}
+
+// Option is functional spec option.
+type Option func(*Options)
+
+// WithLookupEnv sets the function which resolves the placeholders.
+// By default the placeholders are resolved from the environment.
+func WithLookupEnv(lookup func(name string) (string, bool)) Option {
+	return func(o *Options) {
+		o.LookupEnv = lookup
+	}
+}
+
+// Load reads the Spec from the JSON file at path and validates it.
+func Load(path string, opts ...Option) (*Spec, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Decode(f, opts...)
+}
+
+// Decode decodes the Spec from JSON read from r, resolves its
+// placeholders and validates it. It fails if any placeholder
+// variable is not set.
+func Decode(r io.Reader, opts ...Option) (*Spec, error) {
+	options := Options{
+		LookupEnv: os.LookupEnv,
+	}
+	for _, apply := range opts {
+		apply(&options)
+	}
+
+	var raw any
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decode spec: %w", err)
+	}
+	raw, err := expand(raw, options.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	s := new(Spec)
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("decode spec: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Encode writes the Spec to w as indented JSON.
+func (s *Spec) Encode(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// expand replaces the placeholders in the decoded JSON value v.
+func expand(v any, lookup func(string) (string, bool)) (any, error) {
+	switch v := v.(type) {
+	case string:
+		m := placeholderRe.FindStringSubmatch(v)
+		if m == nil {
+			return v, nil
+		}
+		val, ok := lookup(m[1])
+		if !ok {
+			return nil, fmt.Errorf("placeholder %s: variable %s not set", v, m[1])
+		}
+		return val, nil
+	case []any:
+		for i := range v {
+			x, err := expand(v[i], lookup)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = x
+		}
+	case map[string]any:
+		for k := range v {
+			x, err := expand(v[k], lookup)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = x
+		}
+	}
+	return v, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// It rejects unknown fields and records the fields set for every resource.
+func (s *Spec) UnmarshalJSON(data []byte) error {
+	type Alias Spec
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode((*Alias)(s)); err != nil {
+		return err
+	}
+
+	var aux struct {
+		AccountConns map[string]map[string]json.RawMessage `json:"account_connections"`
+		VectorDBs    map[string]map[string]json.RawMessage `json:"vector_databases"`
+		Prompts      map[string]map[string]json.RawMessage `json:"prompts"`
+		Voices       map[string]map[string]json.RawMessage `json:"voices"`
+		Actions      map[string]map[string]json.RawMessage `json:"actions"`
+		Webhooks     map[string]map[string]json.RawMessage `json:"webhooks"`
+		Agents       map[string]map[string]json.RawMessage `json:"agents"`
+		Numbers      map[string]map[string]json.RawMessage `json:"numbers"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	raw := map[Kind]map[string]map[string]json.RawMessage{
+		AccountConnKind: aux.AccountConns,
+		VectorDBKind:    aux.VectorDBs,
+		PromptKind:      aux.Prompts,
+		VoiceKind:       aux.Voices,
+		ActionKind:      aux.Actions,
+		WebhookKind:     aux.Webhooks,
+		AgentKind:       aux.Agents,
+		NumberKind:      aux.Numbers,
+	}
+
+	s.fields = make(map[Kind]map[string][]string)
+	for kind, resources := range raw {
+		s.fields[kind] = make(map[string][]string, len(resources))
+		for name, obj := range resources {
+			fields := make([]string, 0, len(obj))
+			for field := range obj {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			s.fields[kind][name] = fields
+		}
+	}
+	return nil
+}
+
+// Names returns the sorted names of the resources of the kind.
+func (s *Spec) Names(kind Kind) []string {
+	var names []string
+	switch kind {
+	case AccountConnKind:
+		names = keys(s.AccountConns)
+	case VectorDBKind:
+		names = keys(s.VectorDBs)
+	case PromptKind:
+		names = keys(s.Prompts)
+	case VoiceKind:
+		names = keys(s.Voices)
+	case ActionKind:
+		names = keys(s.Actions)
+	case WebhookKind:
+		names = keys(s.Webhooks)
+	case AgentKind:
+		names = keys(s.Agents)
+	case NumberKind:
+		names = keys(s.Numbers)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resource returns the resource of the kind.
+func (s *Spec) resource(kind Kind, name string) (any, bool) {
+	var (
+		v  any
+		ok bool
+	)
+	switch kind {
+	case AccountConnKind:
+		v, ok = s.AccountConns[name]
+	case VectorDBKind:
+		v, ok = s.VectorDBs[name]
+	case PromptKind:
+		v, ok = s.Prompts[name]
+	case VoiceKind:
+		v, ok = s.Voices[name]
+	case ActionKind:
+		v, ok = s.Actions[name]
+	case WebhookKind:
+		v, ok = s.Webhooks[name]
+	case AgentKind:
+		v, ok = s.Agents[name]
+	case NumberKind:
+		v, ok = s.Numbers[name]
+	}
+	return v, ok
+}
+
+// Validate checks that all the references between the resources are valid.
+func (s *Spec) Validate() error {
+	for _, name := range s.Names(AgentKind) {
+		agent := s.Agents[name]
+		if err := s.checkRef(AgentKind, name, PromptKind, agent.Prompt, true); err != nil {
+			return err
+		}
+		if err := s.checkRef(AgentKind, name, VoiceKind, agent.Voice, true); err != nil {
+			return err
+		}
+		for _, action := range agent.Actions {
+			if err := s.checkRef(AgentKind, name, ActionKind, action, true); err != nil {
+				return err
+			}
+		}
+		if err := s.checkRef(AgentKind, name, WebhookKind, agent.Webhook, false); err != nil {
+			return err
+		}
+		if err := s.checkRef(AgentKind, name, VectorDBKind, agent.VectorDB, false); err != nil {
+			return err
+		}
+	}
+	for _, nr := range s.Names(NumberKind) {
+		if err := s.checkRef(NumberKind, nr, AgentKind, s.Numbers[nr].InboundAgent, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Spec) checkRef(kind Kind, name string, refKind Kind, ref string, required bool) error {
+	if ref == "" {
+		if required {
+			return fmt.Errorf("%s %q: missing %s", kind, name, refKind)
+		}
+		return nil
+	}
+	if _, ok := s.resource(refKind, ref); !ok {
+		return fmt.Errorf("%s %q: unknown %s %q", kind, name, refKind, ref)
+	}
+	return nil
+}
+
+func keys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
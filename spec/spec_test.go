@@ -0,0 +1,291 @@
+package spec
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/vocodetest"
+)
+
+const specJSON = `{
+	"prompts": {
+		"support": {"content": %q}
+	},
+	"voices": {
+		"rime": {"type": "voice_rime", "speaker": "young_male", "speed_alpha": 1.2}
+	},
+	"actions": {
+		"hangup": {
+			"type": "action_end_conversation",
+			"action_trigger": {"type": "action_trigger_phrase_based", "config": {"phrase_triggers": [{"phrase": "bye", "conditions": ["phrase_condition_type_contains"]}]}},
+			"config": {}
+		}
+	},
+	"webhooks": {
+		"events": {"subscriptions": ["event_message"], "url": "https://example.com/hook", "method": "POST"}
+	},
+	"agents": {
+		"support": {"name": "Support", "prompt": "support", "voice": "rime", "actions": ["hangup"], "webhook": "events", "language": "en"}
+	},
+	"numbers": {
+		%q: {"label": "support line", "inbound_agent": "support"}
+	}
+}`
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{"unknown_field", `{"prompts": {"p": {"contents": "typo"}}}`, "unknown field"},
+		{"unknown_ref", `{"agents": {"a": {"name": "a", "prompt": "missing", "voice": "v"}}}`, `unknown prompt "missing"`},
+		{"missing_ref", `{"prompts": {"p": {"content": "c"}}, "agents": {"a": {"name": "a", "prompt": "p"}}}`, "missing voice"},
+		{"voice_type", `{"voices": {"v": {"type": "voice_unknown"}}}`, "unsupported voice type"},
+	}
+	for _, tc := range testCases {
+		if _, err := Decode(strings.NewReader(tc.spec)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Fatalf("%s: expected error containing %q, got: %v", tc.name, tc.err, err)
+		}
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	t.Parallel()
+
+	s := vocodetest.NewServer()
+	t.Cleanup(s.Close)
+	c := s.Client()
+	ctx := context.Background()
+
+	number, err := c.BuyNumber(ctx, &vocode.BuyNumberReq{AreaCode: "415", TelProvider: vocode.TwilioTelProvider})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	state, err := LoadState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := func(content string) *Plan {
+		t.Helper()
+		sp, err := Decode(strings.NewReader(fmt.Sprintf(specJSON, content, number.Number)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := sp.Plan(ctx, c, state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	ops := func(p *Plan) map[string]string {
+		ops := make(map[string]string)
+		for _, c := range p.Changes {
+			ops[string(c.Kind)+"."+c.Name] = string(c.Op) + " " + strings.Join(c.Fields, ",")
+		}
+		return ops
+	}
+
+	p := plan("You are a support agent.")
+	exp := map[string]string{
+		"prompt.support":          "create ",
+		"voice.rime":              "create ",
+		"action.hangup":           "create ",
+		"webhook.events":          "create ",
+		"agent.support":           "create ",
+		"number." + number.Number: "update inbound_agent,label",
+	}
+	if got := ops(p); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("expected plan: %v, got: %v", exp, got)
+	}
+	if !strings.HasSuffix(p.String(), "Plan: 5 to create, 1 to update.") {
+		t.Fatalf("unexpected plan:\n%s", p)
+	}
+	if err := p.Apply(ctx, c, state); err != nil {
+		t.Fatal(err)
+	}
+
+	// the state is persisted and the live resources reference each other
+	state, err = LoadState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agentID, _ := state.ID(AgentKind, "support")
+	agent, err := c.GetAgent(ctx, agentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promptID, _ := state.ID(PromptKind, "support")
+	if agent.Prompt == nil || agent.Prompt.ID != promptID {
+		t.Fatalf("expected agent prompt: %s, got: %+v", promptID, agent.Prompt)
+	}
+	live, err := c.GetNumber(ctx, number.Number)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if live.Label != "support line" || live.InboundAgent == nil || live.InboundAgent.ID != agentID {
+		t.Fatalf("unexpected number: %+v", live)
+	}
+
+	// the applied spec has no changes
+	if p := plan("You are a support agent."); p.HasChanges() {
+		t.Fatalf("expected no changes, got:\n%s", p)
+	}
+
+	// only the changed fields are updated and the rest is left intact
+	p = plan("You are a helpful support agent.")
+	if got := ops(p)["prompt.support"]; got != "update content" {
+		t.Fatalf("expected prompt update, got: %s\n%s", got, p)
+	}
+	if err := p.Apply(ctx, c, state); err != nil {
+		t.Fatal(err)
+	}
+	prompt, err := c.GetPrompt(ctx, promptID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prompt.Content != "You are a helpful support agent." {
+		t.Fatalf("unexpected prompt content: %s", prompt.Content)
+	}
+
+	// deleted resources are recreated and their references updated
+	webhookID, _ := state.ID(WebhookKind, "events")
+	if err := c.DeleteWebhook(ctx, webhookID); err != nil {
+		t.Fatal(err)
+	}
+	p = plan("You are a helpful support agent.")
+	if got := ops(p); got["webhook.events"] != "create " || got["agent.support"] != "update webhook" {
+		t.Fatalf("unexpected plan: %v", got)
+	}
+	if err := p.Apply(ctx, c, state); err != nil {
+		t.Fatal(err)
+	}
+	if p := plan("You are a helpful support agent."); p.HasChanges() {
+		t.Fatalf("expected no changes, got:\n%s", p)
+	}
+}
+
+const accountJSON = `{
+	"account_connections": {
+		"twilio": {"type": "account_connection_twilio", "credentials": {"twilio_account_sid": "AC123", "twilio_auth_token": "${TWILIO_TOKEN}"}}
+	},
+	"vector_databases": {
+		"docs": {"type": "vector_database_pinecone", "index": "docs", "api_key": "${PINECONE_KEY}", "api_environment": "us-east1"}
+	},
+	"prompts": {
+		"sales": {"content": "You are a sales agent."}
+	},
+	"voices": {
+		"eleven": {"type": "voice_eleven_labs", "api_key": "${ELEVEN_KEY}", "voice_id": "v1"}
+	},
+	"actions": {
+		"hangup": {
+			"type": "action_end_conversation",
+			"action_trigger": {"type": "action_trigger_phrase_based", "config": {"phrase_triggers": [{"phrase": "bye", "conditions": ["phrase_condition_type_contains"]}]}},
+			"config": {}
+		}
+	},
+	"agents": {
+		"x": {"name": "Sales Team", "prompt": "sales", "voice": "eleven", "actions": ["hangup"], "vector_database": "docs", "language": "en"}
+	},
+	"numbers": {
+		%q: {"label": "sales", "inbound_agent": "x"}
+	}
+}`
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	s := vocodetest.NewServer()
+	t.Cleanup(s.Close)
+	c := s.Client()
+	ctx := context.Background()
+
+	number, err := c.BuyNumber(ctx, &vocode.BuyNumberReq{AreaCode: "415", TelProvider: vocode.TwilioTelProvider})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := map[string]string{
+		"TWILIO_TOKEN": "twilio-secret",
+		"PINECONE_KEY": "pinecone-secret",
+		"ELEVEN_KEY":   "eleven-secret",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := secrets[name]
+		return v, ok
+	}
+
+	if _, err := Decode(strings.NewReader(fmt.Sprintf(accountJSON, number.Number))); err == nil || !strings.Contains(err.Error(), "not set") {
+		t.Fatalf("expected unset placeholder error, got: %v", err)
+	}
+	sp, err := Decode(strings.NewReader(fmt.Sprintf(accountJSON, number.Number)), WithLookupEnv(lookup))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := sp.Plan(ctx, c, NewState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(ctx, c, NewState()); err != nil {
+		t.Fatal(err)
+	}
+
+	state := NewState()
+	exported, err := Export(ctx, c, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	if err := exported.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, secret := range secrets {
+		if strings.Contains(out, secret) {
+			t.Fatalf("expected secret %q to be replaced, got:\n%s", secret, out)
+		}
+	}
+
+	agent, ok := exported.Agents["sales-team"]
+	if !ok {
+		t.Fatalf("expected agent sales-team, got: %v", exported.Names(AgentKind))
+	}
+	if agent.Prompt != "sales-team" || agent.Voice != "eleven-labs" || agent.VectorDB != "docs" ||
+		!reflect.DeepEqual(agent.Actions, []string{"end-conversation"}) {
+		t.Fatalf("unexpected agent references: %+v", agent)
+	}
+	if got := exported.Numbers[number.Number].InboundAgent; got != "sales-team" {
+		t.Fatalf("expected inbound agent: sales-team, got: %s", got)
+	}
+	if got := exported.Voices["eleven-labs"].ElevenLabsVoice.APIKey; got != "${VOCODE_VOICE_ELEVEN_LABS_API_KEY}" {
+		t.Fatalf("unexpected voice api key: %s", got)
+	}
+
+	// the exported spec is up to date with the account
+	secrets = map[string]string{
+		"VOCODE_ACCOUNT_CONNECTION_TWILIO_AUTH_TOKEN": "twilio-secret",
+		"VOCODE_VECTOR_DATABASE_DOCS_API_KEY":         "pinecone-secret",
+		"VOCODE_VOICE_ELEVEN_LABS_API_KEY":            "eleven-secret",
+	}
+	sp, err = Decode(strings.NewReader(out), WithLookupEnv(lookup))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err = sp.Plan(ctx, c, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.HasChanges() {
+		t.Fatalf("expected no changes, got:\n%s", p)
+	}
+}
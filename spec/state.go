@@ -0,0 +1,106 @@
+package spec
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// State records the IDs of the live resources managed by the Spec.
+// It is safe for concurrent use.
+type State struct {
+	mu   sync.Mutex
+	path string
+	ids  map[Kind]map[string]string
+}
+
+// NewState creates a new empty State which is not persisted.
+func NewState() *State {
+	return &State{
+		ids: make(map[Kind]map[string]string),
+	}
+}
+
+// LoadState loads the State from the JSON file at path.
+// It returns an empty State if the file does not exist.
+// The State is saved to path by Save.
+func LoadState(path string) (*State, error) {
+	s := NewState()
+	s.path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.ids); err != nil {
+		return nil, err
+	}
+	if s.ids == nil {
+		s.ids = make(map[Kind]map[string]string)
+	}
+	return s, nil
+}
+
+// ID returns the ID of the named resource of the kind.
+func (s *State) ID(kind Kind, name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.ids[kind][name]
+	return id, ok
+}
+
+// Set sets the ID of the named resource of the kind.
+func (s *State) Set(kind Kind, name, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ids[kind] == nil {
+		s.ids[kind] = make(map[string]string)
+	}
+	s.ids[kind][name] = id
+}
+
+// Delete removes the named resource of the kind.
+func (s *State) Delete(kind Kind, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.ids[kind], name)
+}
+
+// Save writes the State to the file it was loaded from. The file is
+// written atomically. It does nothing if the State is not persisted.
+func (s *State) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.ids, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,119 @@
+package transcript
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// ExportOptions configure the transcript export.
+type ExportOptions struct {
+	// List configures the listing of the calls.
+	List *vocode.ListOptions
+	// Filter selects the calls to export.
+	// All calls are exported if it's not set.
+	Filter func(*vocode.Call) bool
+	// SkipEmpty skips the calls with no transcript.
+	SkipEmpty bool
+}
+
+// ExportOption is functional export option.
+type ExportOption func(*ExportOptions)
+
+// WithListOptions sets the call list options.
+func WithListOptions(opts *vocode.ListOptions) ExportOption {
+	return func(o *ExportOptions) {
+		o.List = opts
+	}
+}
+
+// WithFilter sets the call filter.
+func WithFilter(fn func(*vocode.Call) bool) ExportOption {
+	return func(o *ExportOptions) {
+		o.Filter = fn
+	}
+}
+
+// WithSkipEmpty skips the calls with no transcript.
+func WithSkipEmpty() ExportOption {
+	return func(o *ExportOptions) {
+		o.SkipEmpty = true
+	}
+}
+
+// Export writes the transcripts of all the calls listed via c into dir
+// in the given format, one file per call named after the call ID.
+// The directory is created if it doesn't exist. Every file is written
+// atomically so interrupted exports never leave partial transcripts.
+// The export fails on the call whose ID is not a valid file name.
+// It returns the number of exported calls.
+func Export(ctx context.Context, c *vocode.Client, dir string, f Format, opts ...ExportOption) (int, error) {
+	options := ExportOptions{}
+	for _, apply := range opts {
+		apply(&options)
+	}
+
+	if err := Write(io.Discard, f, &vocode.Call{}); err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	p := c.AllCalls(ctx, options.List)
+	for p.Next() {
+		call := p.Item()
+		if options.SkipEmpty && call.Transcript == "" {
+			continue
+		}
+		if options.Filter != nil && !options.Filter(&call) {
+			continue
+		}
+		path, err := callPath(dir, call.ID, f)
+		if err != nil {
+			return n, err
+		}
+		if err := writeFile(path, f, &call); err != nil {
+			return n, fmt.Errorf("export call %s: %w", call.ID, err)
+		}
+		n++
+	}
+	return n, p.Err()
+}
+
+// callPath returns the path of the call transcript file in dir.
+// The call IDs which are not valid file names are rejected
+// so that the transcripts are never written outside of dir.
+func callPath(dir, id string, f Format) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("invalid call ID: %q", id)
+	}
+	return filepath.Join(dir, id+f.Ext()), nil
+}
+
+// writeFile writes the call transcript to path via a temporary file.
+func writeFile(path string, f Format, call *vocode.Call) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".transcript-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := Write(tmp, f, call); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,239 @@
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+const (
+	// wordDuration is the estimated time it takes to say a word.
+	wordDuration = 400 * time.Millisecond
+	// minCueDuration is the minimum estimated length of the caption cue.
+	minCueDuration = time.Second
+)
+
+// Format is the transcript export format.
+type Format string
+
+const (
+	// VTT is the WebVTT captions format.
+	VTT Format = "vtt"
+	// SRT is the SubRip captions format.
+	SRT Format = "srt"
+	// JSONL is the JSON lines format with one turn per line.
+	JSONL Format = "jsonl"
+	// Markdown is the Markdown format for human review.
+	Markdown Format = "md"
+)
+
+// Ext returns the file extension of the format.
+func (f Format) Ext() string {
+	return "." + string(f)
+}
+
+// Write writes the transcript of the call to w in the given format.
+func Write(w io.Writer, f Format, call *vocode.Call) error {
+	switch f {
+	case VTT:
+		return WriteVTT(w, call)
+	case SRT:
+		return WriteSRT(w, call)
+	case JSONL:
+		return WriteJSONL(w, call)
+	case Markdown:
+		return WriteMarkdown(w, call)
+	default:
+		return fmt.Errorf("unsupported format: %q", f)
+	}
+}
+
+// Cue is the caption cue of the transcript turn.
+type Cue struct {
+	Turn
+	End time.Duration
+}
+
+// Cues returns the caption cues of the call transcript aligned with the call recording.
+// The turn timestamps are used if the transcript is timed. Otherwise the call
+// duration is split between the turns by their word counts, or, if the call
+// duration is not known, every turn length is estimated from its word count.
+func Cues(call *vocode.Call) []Cue {
+	t := FromCall(call)
+	if len(t.Turns) == 0 {
+		return nil
+	}
+	duration := callDuration(call)
+
+	cues := make([]Cue, len(t.Turns))
+	if t.Timed() {
+		for i, turn := range t.Turns {
+			cues[i] = Cue{Turn: turn}
+			switch {
+			case i < len(t.Turns)-1:
+				cues[i].End = t.Turns[i+1].Start
+			case duration > turn.Start:
+				cues[i].End = duration
+			default:
+				cues[i].End = turn.Start + estimate(turn)
+			}
+		}
+		return cues
+	}
+
+	total := 0
+	for _, turn := range t.Turns {
+		total += max(turn.Words(), 1)
+	}
+	var start time.Duration
+	words := 0
+	for i, turn := range t.Turns {
+		words += max(turn.Words(), 1)
+		end := start + estimate(turn)
+		if duration > 0 {
+			end = duration * time.Duration(words) / time.Duration(total)
+		}
+		turn.Start = start
+		cues[i] = Cue{Turn: turn, End: end}
+		start = end
+	}
+	return cues
+}
+
+// callDuration returns the call duration or 0 if it's not known.
+func callDuration(call *vocode.Call) time.Duration {
+	start, err := time.Parse(time.RFC3339Nano, call.StartTime)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse(time.RFC3339Nano, call.EndTime)
+	if err != nil {
+		return 0
+	}
+	return max(end.Sub(start), 0)
+}
+
+// estimate returns the estimated length of the turn.
+func estimate(turn Turn) time.Duration {
+	return max(time.Duration(turn.Words())*wordDuration, minCueDuration)
+}
+
+// speakerName returns the speaker display name.
+func speakerName(s Speaker) string {
+	switch s {
+	case Bot:
+		return "Bot"
+	case Human:
+		return "Human"
+	default:
+		return string(s)
+	}
+}
+
+// timestamp formats d as hh:mm:ss followed by sep and milliseconds.
+func timestamp(d time.Duration, sep string) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// vttEscaper escapes the cue text. The cue text must not contain
+// the "-->" cue timings separator so it is escaped before ">".
+var vttEscaper = strings.NewReplacer("-->", "--&gt;", "&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// WriteVTT writes the call transcript to w as WebVTT captions.
+// The speakers are marked with the WebVTT voice tags.
+func WriteVTT(w io.Writer, call *vocode.Call) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, "WEBVTT\n")
+	for i, cue := range Cues(call) {
+		fmt.Fprintf(bw, "\n%d\n%s --> %s\n", i+1, timestamp(cue.Start, "."), timestamp(cue.End, "."))
+		fmt.Fprintf(bw, "<v %s>%s\n", speakerName(cue.Speaker), vttEscaper.Replace(cue.Text))
+	}
+	return bw.Flush()
+}
+
+// WriteSRT writes the call transcript to w as SubRip captions.
+func WriteSRT(w io.Writer, call *vocode.Call) error {
+	bw := bufio.NewWriter(w)
+	for i, cue := range Cues(call) {
+		if i > 0 {
+			fmt.Fprint(bw, "\n")
+		}
+		fmt.Fprintf(bw, "%d\n%s --> %s\n", i+1, timestamp(cue.Start, ","), timestamp(cue.End, ","))
+		fmt.Fprintf(bw, "%s: %s\n", speakerName(cue.Speaker), cue.Text)
+	}
+	return bw.Flush()
+}
+
+// Record is the JSONL transcript record.
+type Record struct {
+	CallID  string  `json:"call_id"`
+	AgentID string  `json:"agent_id,omitempty"`
+	Index   int     `json:"index"`
+	Speaker Speaker `json:"speaker"`
+	Text    string  `json:"text"`
+	// Start is the turn start offset in seconds.
+	// It's only set if the transcript is timed.
+	Start *float64 `json:"start,omitempty"`
+}
+
+// WriteJSONL writes the call transcript to w as JSON lines, one turn per line.
+func WriteJSONL(w io.Writer, call *vocode.Call) error {
+	var agentID string
+	if call.Agent != nil {
+		agentID = call.Agent.ID
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for i, turn := range FromCall(call).Turns {
+		rec := Record{
+			CallID:  call.ID,
+			AgentID: agentID,
+			Index:   i,
+			Speaker: turn.Speaker,
+			Text:    turn.Text,
+		}
+		if turn.Timed {
+			start := turn.Start.Seconds()
+			rec.Start = &start
+		}
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteMarkdown writes the call transcript to w as Markdown for human review.
+func WriteMarkdown(w io.Writer, call *vocode.Call) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Call %s\n\n", call.ID)
+	if call.Agent != nil {
+		fmt.Fprintf(bw, "- **Agent:** %s\n", call.Agent.ID)
+	}
+	fmt.Fprintf(bw, "- **From:** %s\n", call.FromNumber)
+	fmt.Fprintf(bw, "- **To:** %s\n", call.ToNumber)
+	fmt.Fprintf(bw, "- **Status:** %s\n", call.Status)
+	if call.StageOutcome != "" {
+		fmt.Fprintf(bw, "- **Outcome:** %s\n", call.StageOutcome)
+	}
+	if call.StartTime != "" {
+		fmt.Fprintf(bw, "- **Started:** %s\n", call.StartTime)
+	}
+
+	for _, turn := range FromCall(call).Turns {
+		fmt.Fprintf(bw, "\n**%s**", speakerName(turn.Speaker))
+		if turn.Timed {
+			fmt.Fprintf(bw, " `%s`", timestamp(turn.Start, "."))
+		}
+		// NOTE: the trailing double space is the Markdown line break.
+		fmt.Fprintf(bw, ": %s\n", strings.ReplaceAll(turn.Text, "\n", "  \n"))
+	}
+	return bw.Flush()
+}
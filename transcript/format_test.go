@@ -0,0 +1,153 @@
+package transcript
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/vocodetest"
+)
+
+func testCall(transcript string) *vocode.Call {
+	return &vocode.Call{
+		ID:         "call-1",
+		Status:     vocode.CallEnded,
+		Transcript: transcript,
+		FromNumber: "+15550000",
+		ToNumber:   "+15550001",
+		Agent:      &vocode.Agent{ID: "agent-1"},
+		StartTime:  "2024-05-01T10:00:00Z",
+		EndTime:    "2024-05-01T10:00:10Z",
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	untimed := testCall("BOT: Hi <there> & welcome\nHUMAN: Hello\nworld-->")
+	timed := testCall("[00:00] BOT: Hi\n[00:02.5] HUMAN: Hello")
+
+	testCases := []struct {
+		name   string
+		format Format
+		call   *vocode.Call
+		exp    string
+	}{
+		{"vtt", VTT, untimed, "WEBVTT\n\n" +
+			"1\n00:00:00.000 --> 00:00:06.666\n<v Bot>Hi &lt;there&gt; &amp; welcome\n\n" +
+			"2\n00:00:06.666 --> 00:00:10.000\n<v Human>Hello\nworld--&gt;\n"},
+		{"srt", SRT, timed, "" +
+			"1\n00:00:00,000 --> 00:00:02,500\nBot: Hi\n\n" +
+			"2\n00:00:02,500 --> 00:00:10,000\nHuman: Hello\n"},
+		{"jsonl", JSONL, timed, "" +
+			`{"call_id":"call-1","agent_id":"agent-1","index":0,"speaker":"bot","text":"Hi","start":0}` + "\n" +
+			`{"call_id":"call-1","agent_id":"agent-1","index":1,"speaker":"human","text":"Hello","start":2.5}` + "\n"},
+		{"markdown", Markdown, untimed, "# Call call-1\n\n" +
+			"- **Agent:** agent-1\n- **From:** +15550000\n- **To:** +15550001\n- **Status:** ended\n- **Started:** 2024-05-01T10:00:00Z\n\n" +
+			"**Bot**: Hi <there> & welcome\n\n" +
+			"**Human**: Hello  \nworld-->\n"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := Write(&buf, tc.format, tc.call); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tc.exp {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tc.exp, buf.String())
+			}
+		})
+	}
+
+	if err := Write(&bytes.Buffer{}, Format("doc"), timed); err == nil {
+		t.Fatal("expected unsupported format error")
+	}
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	s := vocodetest.NewServer()
+	t.Cleanup(s.Close)
+	c := s.Client()
+	ctx := context.Background()
+
+	prompt, err := c.CreatePrompt(ctx, &vocode.CreatePromptReq{PromptReq: vocode.PromptReq{Content: "test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	voice, err := c.CreateVoice(ctx, &vocode.CreateVoiceReq{VoiceReq: vocode.VoiceReq{Type: vocode.RimeVoiceType, RimeVoice: &vocode.RimeVoice{Speaker: "test"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent, err := c.CreateAgent(ctx, &vocode.CreateAgentReq{AgentReq: vocode.AgentReq{Name: "test", Prompt: prompt.ID, Voice: voice.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	number, err := c.BuyNumber(ctx, &vocode.BuyNumberReq{AreaCode: "415", TelProvider: vocode.TwilioTelProvider})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, to := range []string{"+15550001", "+15550002"} {
+		call, err := c.CreateCall(ctx, &vocode.CreateCallReq{FromNr: number.Number, ToNr: to, Agent: agent.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, call.ID)
+	}
+	// only the first call has finished and has a transcript
+	if _, err := s.CompleteCall(ids[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "transcripts")
+	n, err := Export(ctx, c, dir, Markdown, WithSkipEmpty())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected exported calls: %d, got: %d", 1, n)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ids[0]+".md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "**Human**: I am just testing.") {
+		t.Fatalf("unexpected transcript:\n%s", data)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected files: %d, got: %d", 1, len(entries))
+	}
+}
+
+func TestCallPath(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join("exports", "transcripts")
+
+	path, err := callPath(dir, "call-1", Markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := filepath.Join(dir, "call-1.md"); path != exp {
+		t.Fatalf("expected path: %s, got: %s", exp, path)
+	}
+
+	for _, id := range []string{"", ".", "..", "../call-1", "calls/call-1", `..\call-1`} {
+		if _, err := callPath(dir, id, Markdown); err == nil {
+			t.Fatalf("expected invalid call ID error: %q", id)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Package transcript parses Vocode call transcripts.
+package transcript
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// Speaker is the transcript turn speaker.
+type Speaker string
+
+const (
+	// Bot is the call agent.
+	Bot Speaker = "bot"
+	// Human is the called person.
+	Human Speaker = "human"
+)
+
+// speakers maps the transcript speaker labels to speakers.
+var speakers = map[string]Speaker{
+	"bot":       Bot,
+	"agent":     Bot,
+	"assistant": Bot,
+	"ai":        Bot,
+	"human":     Human,
+	"user":      Human,
+	"caller":    Human,
+}
+
+// lineRe matches the transcript line with the optional timestamp
+// e.g. "BOT: Hello", "[00:01:02] HUMAN: Hi" or "[12.5] BOT: Hey".
+var lineRe = regexp.MustCompile(`^\s*(?:\[?((?:\d+:)?\d+:\d+(?:\.\d+)?|\d+(?:\.\d+)?)\]?\s+)?([A-Za-z]+)\s*:\s?(.*)$`)
+
+// Turn is the single speaker turn of the transcript.
+type Turn struct {
+	Speaker Speaker
+	Text    string
+	// Start is the time offset of the turn from the start of the call.
+	// It's only valid if Timed is true.
+	Start time.Duration
+	Timed bool
+}
+
+// Words returns the number of words in the turn.
+func (t Turn) Words() int {
+	return len(strings.Fields(t.Text))
+}
+
+// Transcript is the parsed call transcript.
+type Transcript struct {
+	Turns []Turn
+}
+
+// Parse parses the transcript. Every line starting with a known speaker
+// label, optionally preceded by a timestamp, starts a new turn; any other
+// lines continue the previous turn. Lines before the first turn are ignored.
+// The lines are not limited in length so the whole transcript is parsed.
+func Parse(s string) *Transcript {
+	t := &Transcript{}
+
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if m := lineRe.FindStringSubmatch(line); m != nil {
+			if speaker, ok := speakers[strings.ToLower(m[2])]; ok {
+				turn := Turn{Speaker: speaker, Text: strings.TrimSpace(m[3])}
+				if m[1] != "" {
+					if d, err := parseTimestamp(m[1]); err == nil {
+						turn.Start, turn.Timed = d, true
+					}
+				}
+				t.Turns = append(t.Turns, turn)
+				continue
+			}
+		}
+
+		if n := len(t.Turns); n > 0 {
+			last := &t.Turns[n-1]
+			last.Text = strings.TrimSpace(last.Text + "\n" + strings.TrimSpace(line))
+		}
+	}
+
+	return t
+}
+
+// FromCall parses the transcript of the call.
+func FromCall(call *vocode.Call) *Transcript {
+	return Parse(call.Transcript)
+}
+
+// parseTimestamp parses the [[hh:]mm:]ss[.fff] timestamp.
+func parseTimestamp(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	secs, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil {
+		return 0, err
+	}
+	d := time.Duration(secs * float64(time.Second))
+	mult := time.Minute
+	for i := len(parts) - 2; i >= 0; i-- {
+		v, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(v) * mult
+		mult *= 60
+	}
+	return d, nil
+}
+
+// First returns the first turn of the speaker.
+func (t *Transcript) First(speaker Speaker) (Turn, bool) {
+	for _, turn := range t.Turns {
+		if turn.Speaker == speaker {
+			return turn, true
+		}
+	}
+	return Turn{}, false
+}
+
+// Last returns the last turn of the speaker.
+func (t *Transcript) Last(speaker Speaker) (Turn, bool) {
+	for i := len(t.Turns) - 1; i >= 0; i-- {
+		if t.Turns[i].Speaker == speaker {
+			return t.Turns[i], true
+		}
+	}
+	return Turn{}, false
+}
+
+// FirstHuman returns the first human utterance.
+func (t *Transcript) FirstHuman() (Turn, bool) {
+	return t.First(Human)
+}
+
+// LastBot returns the last bot message.
+func (t *Transcript) LastBot() (Turn, bool) {
+	return t.Last(Bot)
+}
+
+// Words returns the number of words spoken by the speaker.
+func (t *Transcript) Words(speaker Speaker) int {
+	n := 0
+	for _, turn := range t.Turns {
+		if turn.Speaker == speaker {
+			n += turn.Words()
+		}
+	}
+	return n
+}
+
+// Timed returns true if all the turns have timestamps.
+func (t *Transcript) Timed() bool {
+	for _, turn := range t.Turns {
+		if !turn.Timed {
+			return false
+		}
+	}
+	return len(t.Turns) > 0
+}
+
+// TalkTime returns the time the speaker talked for. The turn lasts until
+// the next turn starts; the length of the last turn is unknown so it is
+// not counted. It returns false if the transcript is not timed.
+func (t *Transcript) TalkTime(speaker Speaker) (time.Duration, bool) {
+	if !t.Timed() {
+		return 0, false
+	}
+	var d time.Duration
+	for i := 0; i < len(t.Turns)-1; i++ {
+		if t.Turns[i].Speaker == speaker {
+			d += max(t.Turns[i+1].Start-t.Turns[i].Start, 0)
+		}
+	}
+	return d, true
+}
+
+// TalkRatio returns the fraction of the conversation the speaker talked for.
+// It's computed from the talk time if the transcript is timed, otherwise
+// from the number of spoken words. It returns 0 for empty transcripts.
+func (t *Transcript) TalkRatio(speaker Speaker) float64 {
+	if t.Timed() {
+		var total time.Duration
+		for _, s := range t.speakers() {
+			d, _ := t.TalkTime(s)
+			total += d
+		}
+		if total > 0 {
+			d, _ := t.TalkTime(speaker)
+			return float64(d) / float64(total)
+		}
+	}
+
+	total := 0
+	for _, turn := range t.Turns {
+		total += turn.Words()
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(t.Words(speaker)) / float64(total)
+}
+
+// speakers returns all the transcript speakers.
+func (t *Transcript) speakers() []Speaker {
+	seen := make(map[Speaker]bool)
+	var speakers []Speaker
+	for _, turn := range t.Turns {
+		if !seen[turn.Speaker] {
+			seen[turn.Speaker] = true
+			speakers = append(speakers, turn.Speaker)
+		}
+	}
+	return speakers
+}
+
+// String returns the transcript in the Vocode transcript format.
+func (t *Transcript) String() string {
+	var sb strings.Builder
+	for i, turn := range t.Turns {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "%s: %s", strings.ToUpper(string(turn.Speaker)), turn.Text)
+	}
+	return sb.String()
+}
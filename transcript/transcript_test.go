@@ -0,0 +1,87 @@
+package transcript
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	t.Run("plain", func(t *testing.T) {
+		t.Parallel()
+		tr := Parse("BOT: Hello, how can I help?\nHUMAN: I'd like to book\na table.\n\nBOT: Sure thing!\n")
+
+		exp := []Turn{
+			{Speaker: Bot, Text: "Hello, how can I help?"},
+			{Speaker: Human, Text: "I'd like to book\na table."},
+			{Speaker: Bot, Text: "Sure thing!"},
+		}
+		if !reflect.DeepEqual(tr.Turns, exp) {
+			t.Fatalf("expected turns: %+v, got: %+v", exp, tr.Turns)
+		}
+		if turn, ok := tr.FirstHuman(); !ok || turn.Text != exp[1].Text {
+			t.Fatalf("unexpected first human turn: %+v", turn)
+		}
+		if turn, ok := tr.LastBot(); !ok || turn.Text != "Sure thing!" {
+			t.Fatalf("unexpected last bot turn: %+v", turn)
+		}
+		if tr.Words(Bot) != 7 || tr.Words(Human) != 6 {
+			t.Fatalf("unexpected word counts: bot %d, human %d", tr.Words(Bot), tr.Words(Human))
+		}
+		if r := tr.TalkRatio(Bot); r != 7.0/13.0 {
+			t.Fatalf("expected talk ratio: %v, got: %v", 7.0/13.0, r)
+		}
+		if _, ok := tr.TalkTime(Bot); ok {
+			t.Fatal("expected untimed transcript")
+		}
+		if s := tr.String(); s != "BOT: Hello, how can I help?\nHUMAN: I'd like to book\na table.\nBOT: Sure thing!" {
+			t.Fatalf("unexpected transcript: %q", s)
+		}
+	})
+	t.Run("timed", func(t *testing.T) {
+		t.Parallel()
+		tr := Parse("[00:00] BOT: Hi\n[00:04.5] HUMAN: Hello there\n[1:00:10] agent: Bye")
+
+		starts := []time.Duration{0, 4500 * time.Millisecond, time.Hour + 10*time.Second}
+		for i, turn := range tr.Turns {
+			if !turn.Timed || turn.Start != starts[i] {
+				t.Fatalf("expected turn start: %v, got: %+v", starts[i], turn)
+			}
+		}
+		if tr.Turns[2].Speaker != Bot {
+			t.Fatalf("expected speaker: %s, got: %s", Bot, tr.Turns[2].Speaker)
+		}
+		d, ok := tr.TalkTime(Human)
+		if !ok || d != time.Hour+10*time.Second-4500*time.Millisecond {
+			t.Fatalf("unexpected human talk time: %v", d)
+		}
+		if r := tr.TalkRatio(Bot); r <= 0 || r >= 0.01 {
+			t.Fatalf("unexpected bot talk ratio: %v", r)
+		}
+	})
+	t.Run("long_line", func(t *testing.T) {
+		t.Parallel()
+		long := strings.Repeat("a", 2*1024*1024)
+		tr := Parse("BOT: " + long + "\nHUMAN: bye")
+
+		exp := []Turn{
+			{Speaker: Bot, Text: long},
+			{Speaker: Human, Text: "bye"},
+		}
+		if !reflect.DeepEqual(tr.Turns, exp) {
+			t.Fatalf("expected turns: %d, got: %d", len(exp), len(tr.Turns))
+		}
+	})
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		tr := Parse("")
+		if len(tr.Turns) != 0 || tr.TalkRatio(Bot) != 0 {
+			t.Fatalf("unexpected transcript: %+v", tr)
+		}
+		if _, ok := tr.FirstHuman(); ok {
+			t.Fatal("expected no human turn")
+		}
+	})
+}
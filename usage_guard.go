@@ -0,0 +1,175 @@
+package vocode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultUsageTTL is the default time the fetched usage is cached for.
+	DefaultUsageTTL = time.Minute
+)
+
+var (
+	// ErrUsageExhausted is returned by CreateCall when the monthly
+	// usage limit minus the safety margin has been reached.
+	ErrUsageExhausted = errors.New("usage exhausted")
+)
+
+// UsageGuard checks the remaining monthly usage minutes
+// before any call is created. See WithUsageGuard.
+// It is safe for concurrent use.
+type UsageGuard struct {
+	opts UsageGuardOptions
+
+	mu      sync.Mutex
+	usage   *Usage
+	fetched time.Time
+	crossed map[float64]bool
+}
+
+// UsageGuardOptions configure the UsageGuard.
+type UsageGuardOptions struct {
+	// TTL is the time the fetched usage is cached for.
+	TTL time.Duration
+	// MarginMinutes is the safety margin: calls are rejected
+	// once the remaining minutes drop to or below it.
+	MarginMinutes int
+	// Thresholds are the used fractions of the monthly
+	// limit e.g. 0.8 and 0.95 which trigger OnThreshold.
+	Thresholds []float64
+	// OnThreshold is called once when the usage crosses the threshold.
+	// It's called again only after the usage drops below the threshold.
+	OnThreshold func(threshold float64, usage Usage)
+	// Clock is used for the usage caching.
+	Clock Clock
+}
+
+// UsageGuardOption is functional usage guard option.
+type UsageGuardOption func(*UsageGuardOptions)
+
+// NewUsageGuard creates a new UsageGuard and returns it.
+func NewUsageGuard(opts ...UsageGuardOption) *UsageGuard {
+	options := UsageGuardOptions{
+		TTL:   DefaultUsageTTL,
+		Clock: DefaultClock,
+	}
+	for _, apply := range opts {
+		apply(&options)
+	}
+
+	return &UsageGuard{
+		opts:    options,
+		crossed: make(map[float64]bool),
+	}
+}
+
+// WithUsageTTL sets the time the fetched usage is cached for.
+func WithUsageTTL(ttl time.Duration) UsageGuardOption {
+	return func(o *UsageGuardOptions) {
+		o.TTL = ttl
+	}
+}
+
+// WithUsageMargin sets the safety margin in minutes.
+func WithUsageMargin(minutes int) UsageGuardOption {
+	return func(o *UsageGuardOptions) {
+		o.MarginMinutes = minutes
+	}
+}
+
+// WithUsageThresholds sets the usage thresholds and their callback.
+func WithUsageThresholds(fn func(threshold float64, usage Usage), thresholds ...float64) UsageGuardOption {
+	return func(o *UsageGuardOptions) {
+		o.OnThreshold = fn
+		o.Thresholds = thresholds
+	}
+}
+
+// WithUsageClock sets the usage guard clock.
+func WithUsageClock(c Clock) UsageGuardOption {
+	return func(o *UsageGuardOptions) {
+		o.Clock = c
+	}
+}
+
+// Check returns the usage fetched via c, or cached if it has not expired yet.
+// It returns error wrapping ErrUsageExhausted if there are no minutes left
+// above the safety margin. Unlimited plans are never exhausted.
+func (g *UsageGuard) Check(ctx context.Context, c *Client) (*Usage, error) {
+	usage, err := g.get(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	if usage.PlanType == PlanUnlimited || usage.MonthlyLimitMinutes <= 0 {
+		return usage, nil
+	}
+	remaining := usage.MonthlyLimitMinutes - usage.MonthlyMinutes
+	if remaining <= g.opts.MarginMinutes {
+		return usage, fmt.Errorf("%w: %d of %d monthly minutes used", ErrUsageExhausted, usage.MonthlyMinutes, usage.MonthlyLimitMinutes)
+	}
+	return usage, nil
+}
+
+// Invalidate drops the cached usage so the next Check fetches it again.
+func (g *UsageGuard) Invalidate() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.usage = nil
+}
+
+func (g *UsageGuard) get(ctx context.Context, c *Client) (*Usage, error) {
+	g.mu.Lock()
+	if g.usage != nil && g.opts.Clock.Now().Sub(g.fetched) < g.opts.TTL {
+		usage := *g.usage
+		g.mu.Unlock()
+		return &usage, nil
+	}
+	g.mu.Unlock()
+
+	usage, err := c.GetUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	g.usage = usage
+	g.fetched = g.opts.Clock.Now()
+	crossed := g.cross(usage)
+	g.mu.Unlock()
+
+	// NOTE: the callback is called without holding the lock
+	// so it can safely call back into the guard or the client.
+	for _, t := range crossed {
+		g.opts.OnThreshold(t, *usage)
+	}
+
+	u := *usage
+	return &u, nil
+}
+
+// cross returns the thresholds the usage has newly crossed.
+// It must be called with the guard mutex held.
+func (g *UsageGuard) cross(usage *Usage) []float64 {
+	if g.opts.OnThreshold == nil || usage.MonthlyLimitMinutes <= 0 {
+		return nil
+	}
+	used := float64(usage.MonthlyMinutes) / float64(usage.MonthlyLimitMinutes)
+
+	var crossed []float64
+	for _, t := range g.opts.Thresholds {
+		switch {
+		case used >= t && !g.crossed[t]:
+			g.crossed[t] = true
+			crossed = append(crossed, t)
+		case used < t:
+			g.crossed[t] = false
+		}
+	}
+	return crossed
+}
@@ -0,0 +1,75 @@
+package vocode_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/vocodetest"
+)
+
+// manualClock returns the time it's set to.
+type manualClock struct{ now time.Time }
+
+func (c *manualClock) Now() time.Time                       { return c.now }
+func (c *manualClock) After(time.Duration) <-chan time.Time { return nil }
+
+func TestUsageGuard(t *testing.T) {
+	t.Parallel()
+
+	s := vocodetest.NewServer(vocodetest.WithPlan(vocode.PlanDeveloper, 100))
+	t.Cleanup(s.Close)
+	ctx := context.Background()
+
+	clock := &manualClock{now: time.Now()}
+	var crossed []float64
+	guard := vocode.NewUsageGuard(
+		vocode.WithUsageTTL(time.Minute),
+		vocode.WithUsageMargin(5),
+		vocode.WithUsageThresholds(func(threshold float64, _ vocode.Usage) {
+			crossed = append(crossed, threshold)
+		}, 0.8, 0.95),
+		vocode.WithUsageClock(clock),
+	)
+	c := s.Client(vocode.WithUsageGuard(guard))
+
+	s.SetUsage(85)
+	if _, err := guard.Check(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(crossed, []float64{0.8}) {
+		t.Fatalf("expected crossed thresholds: %v, got: %v", []float64{0.8}, crossed)
+	}
+
+	// the usage is cached until the TTL expires
+	s.SetUsage(96)
+	if _, err := guard.Check(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	_, err := c.CreateCall(ctx, &vocode.CreateCallReq{FromNr: "+15550000", ToNr: "+15550001", Agent: "agent"})
+	if !errors.Is(err, vocode.ErrUsageExhausted) {
+		t.Fatalf("expected error: %v, got: %v", vocode.ErrUsageExhausted, err)
+	}
+	if !reflect.DeepEqual(crossed, []float64{0.8, 0.95}) {
+		t.Fatalf("expected crossed thresholds: %v, got: %v", []float64{0.8, 0.95}, crossed)
+	}
+
+	// the thresholds are re-armed when the usage drops e.g. at the start of the month
+	s.SetUsage(0)
+	guard.Invalidate()
+	if _, err := guard.Check(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	s.SetUsage(80)
+	guard.Invalidate()
+	if _, err := guard.Check(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if len(crossed) != 3 {
+		t.Fatalf("expected re-armed threshold, got: %v", crossed)
+	}
+}
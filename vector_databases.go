@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/url"
 
@@ -73,11 +74,9 @@ func (c *Client) ListVectorDBs(ctx context.Context, paging *PageParams) (*Vector
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
+	var options []request.HTTPOption
 	if paging != nil {
-		request.WithPageParams(paging.Encode())
+		options = append(options, request.WithPageParams(paging.Encode()))
 	}
 
 	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
@@ -85,7 +84,7 @@ func (c *Client) ListVectorDBs(ctx context.Context, paging *PageParams) (*Vector
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -98,17 +97,24 @@ func (c *Client) ListVectorDBs(ctx context.Context, paging *PageParams) (*Vector
 	return vectorDBs, nil
 }
 
+// AllVectorDBs returns Pager which iterates over all the vector databases.
+func (c *Client) AllVectorDBs(ctx context.Context, opts *ListOptions) *Pager[VectorDB] {
+	return newPager(ctx, opts, func(ctx context.Context, paging *PageParams) ([]VectorDB, *Paging, error) {
+		page, err := c.ListVectorDBs(ctx, paging)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Items, page.Paging, nil
+	})
+}
+
 func (c *Client) GetVectorDB(ctx context.Context, id string) (*VectorDB, error) {
 	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/vector_databases")
 	if err != nil {
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
+	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -116,7 +122,7 @@ func (c *Client) GetVectorDB(ctx context.Context, id string) (*VectorDB, error)
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -142,16 +148,12 @@ func (c *Client) CreateVectorDB(ctx context.Context, createReq *CreateVectorDBRe
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -177,11 +179,7 @@ func (c *Client) UpdateVectorDB(ctx context.Context, id string, updateReq *Updat
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -189,7 +187,7 @@ func (c *Client) UpdateVectorDB(ctx context.Context, id string, updateReq *Updat
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -201,3 +199,29 @@ func (c *Client) UpdateVectorDB(ctx context.Context, id string, updateReq *Updat
 	}
 	return vectorDB, nil
 }
+
+// DeleteVectorDB deletes the vector database with the given id.
+// It returns error matching ErrNotFound if the vector database does not exist.
+func (c *Client) DeleteVectorDB(ctx context.Context, id string) error {
+	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/vector_databases/delete")
+	if err != nil {
+		return err
+	}
+
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	q.Add("id", id)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
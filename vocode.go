@@ -1,9 +1,15 @@
 package vocode
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"os"
+	"sync"
 
 	"github.com/milosgajdos/go-vocode/client"
+	"github.com/milosgajdos/go-vocode/credentials"
+	"github.com/milosgajdos/go-vocode/request"
 )
 
 const (
@@ -16,26 +22,34 @@ const (
 // Client is an Vocode HTTP API client.
 type Client struct {
 	opts Options
+
+	mu     sync.Mutex
+	userID string
 }
 
 type Options struct {
-	APIKey     string
-	UserID     string
-	BaseURL    string
-	Version    string
-	HTTPClient *client.HTTP
+	APIKey string
+	// Credentials provide the API key for every request.
+	// APIKey is used if they're not set.
+	Credentials credentials.Provider
+	UserID      string
+	BaseURL     string
+	Version     string
+	HTTPClient  *client.HTTP
+	UsageGuard  *UsageGuard
 }
 
 // Option is functional graph option.
 type Option func(*Options)
 
 // NewClient creates a new HTTP API client and returns it.
-// By default it reads the secret key from PLAYHT_SECRET_KEY env var
-// and user ID from PLAYHT_USER_ID env var and uses
+// By default it reads the API key from VOCODE_API_KEY env var
+// and user ID from VOCODE_USER_ID env var and uses
 // the default http client for making the HTTP api requests.
 func NewClient(opts ...Option) *Client {
 	options := Options{
 		APIKey:     os.Getenv("VOCODE_API_KEY"),
+		UserID:     os.Getenv("VOCODE_USER_ID"),
 		BaseURL:    BaseURL,
 		Version:    APIV1,
 		HTTPClient: client.NewHTTP(),
@@ -51,9 +65,26 @@ func NewClient(opts ...Option) *Client {
 }
 
 // WithAPIKey sets the secret key.
+// It replaces the credentials set via WithCredentials.
 func WithAPIKey(apiKey string) Option {
 	return func(o *Options) {
 		o.APIKey = apiKey
+		o.Credentials = nil
+	}
+}
+
+// WithCredentials sets the credentials provider
+// which provides the API key for every request.
+func WithCredentials(p credentials.Provider) Option {
+	return func(o *Options) {
+		o.Credentials = p
+	}
+}
+
+// WithUserID sets the ID of the user owning the API key.
+func WithUserID(userID string) Option {
+	return func(o *Options) {
+		o.UserID = userID
 	}
 }
 
@@ -77,3 +108,75 @@ func WithHTTPClient(httpClient *client.HTTP) Option {
 		o.HTTPClient = httpClient
 	}
 }
+
+// WithUsageGuard sets the usage guard which
+// checks the remaining minutes before creating calls.
+func WithUsageGuard(g *UsageGuard) Option {
+	return func(o *Options) {
+		o.UsageGuard = g
+	}
+}
+
+// UserID returns the ID of the user owning the API key.
+// Unless it's set via the options, it's read from the API usage.
+func (c *Client) UserID(ctx context.Context) (string, error) {
+	if c.opts.UserID != "" {
+		return c.opts.UserID, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.userID == "" {
+		u, err := c.GetUsage(ctx)
+		if err != nil {
+			return "", err
+		}
+		c.userID = u.UserID
+	}
+	return c.userID, nil
+}
+
+// apiKey returns the API key provided by the credentials.
+func (c *Client) apiKey(ctx context.Context) (string, error) {
+	if c.opts.Credentials == nil {
+		return c.opts.APIKey, nil
+	}
+	return c.opts.Credentials.APIKey(ctx)
+}
+
+// do authorizes the request with the API key and sends it. If the API
+// rejects the key and the credentials implement credentials.Refresher,
+// they're refreshed and the request is retried once with the new key.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	key, err := c.apiKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	request.WithBearer(key)(req)
+
+	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	if err == nil || !errors.Is(err, ErrUnauthorized) {
+		return resp, err
+	}
+
+	r, ok := c.opts.Credentials.(credentials.Refresher)
+	if !ok || (req.Body != nil && req.GetBody == nil) {
+		return nil, err
+	}
+	r.Refresh()
+	newKey, keyErr := c.apiKey(ctx)
+	if keyErr != nil || newKey == key {
+		return nil, err
+	}
+
+	retry := req.Clone(ctx)
+	if req.GetBody != nil {
+		if retry.Body, keyErr = req.GetBody(); keyErr != nil {
+			return nil, err
+		}
+	}
+	request.WithBearer(newKey)(retry)
+	return request.Do[*APIError](c.opts.HTTPClient, retry)
+}
@@ -0,0 +1,163 @@
+package vocodetest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+func (s *Server) listCalls(r *http.Request) (any, *apiError) {
+	p, apiErr := paginate(s.calls.list(), r.URL.Query())
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	items := make([]object, 0, len(p.Items))
+	for _, obj := range p.Items {
+		items = append(items, s.expandCall(obj))
+	}
+	p.Items = items
+	return p, nil
+}
+
+func (s *Server) getCall(r *http.Request) (any, *apiError) {
+	obj, apiErr := lookup(s.calls, r, "id")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if s.opts.AutoAdvance {
+		s.advance(obj)
+	}
+	return s.expandCall(obj), nil
+}
+
+func (s *Server) createCall(r *http.Request) (any, *apiError) {
+	body, apiErr := decodeBody(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if apiErr := requireString(body, "to_number"); apiErr != nil {
+		return nil, apiErr
+	}
+	if apiErr := requireString(body, "from_number"); apiErr != nil {
+		return nil, apiErr
+	}
+	number, ok := s.numbers.get(body["from_number"].(string))
+	if !ok {
+		return nil, notFound(s.numbers.kind)
+	}
+	if apiErr := resolveRef(body, "agent", s.agents, true); apiErr != nil {
+		return nil, apiErr
+	}
+	if body["on_no_human_answer"] == nil || body["on_no_human_answer"] == "" {
+		body["on_no_human_answer"] = string(vocode.ContinueCallOnNoHumanAnswer)
+	}
+	if apiErr := requireOneOf(body, "on_no_human_answer", vocode.ContinueCallOnNoHumanAnswer, vocode.HangupCallOnNoHumanAnswer); apiErr != nil {
+		return nil, apiErr
+	}
+
+	obj := object{
+		"id":                           newID(),
+		"user_id":                      s.opts.UserID,
+		"status":                       string(vocode.CallNotStarted),
+		"error_message":                nil,
+		"recording_available":          false,
+		"transcript":                   nil,
+		"human_detection_result":       nil,
+		"do_not_call_result":           false,
+		"telephony_id":                 newID(),
+		"stage":                        string(vocode.CallCreated),
+		"stage_outcome":                nil,
+		"telephony_metadata":           nil,
+		"from_number":                  body["from_number"],
+		"to_number":                    body["to_number"],
+		"agent":                        body["agent"],
+		"telephony_provider":           number["telephony_provider"],
+		"agent_phone_number":           body["from_number"],
+		"start_time":                   time.Now().UTC().Format(time.RFC3339),
+		"end_time":                     nil,
+		"hipaa_compliant":              body["hipaa_compliant"] == true,
+		"on_no_human_answer":           body["on_no_human_answer"],
+		"context":                      body["context"],
+		"run_do_not_call_detection":    body["run_do_not_call_detection"] == true,
+		"telephony_account_connection": number["telephony_account_connection"],
+		"telephony_params":             nil,
+	}
+	s.calls.put(obj["id"].(string), obj)
+	s.startLifecycle(obj)
+
+	return s.expandCall(obj), nil
+}
+
+func (s *Server) endCall(r *http.Request) (any, *apiError) {
+	obj, apiErr := lookup(s.calls, r, "id")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if lc, ok := s.lifecycles[obj["id"].(string)]; ok {
+		lc.script.Status = vocode.CallEnded
+		lc.script.Outcome = vocode.CallStageBotDisconnect
+		s.finish(obj, lc)
+	}
+	return s.expandCall(obj), nil
+}
+
+func (s *Server) getRecording(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	obj, apiErr := lookup(s.calls, r, "id")
+	if apiErr == nil && obj["recording_available"] != true {
+		apiErr = notFound("Recording")
+	}
+	var data []byte
+	if apiErr == nil {
+		data = s.recordings[obj["id"].(string)]
+		if data == nil {
+			data = silentWAV(time.Second)
+		}
+	}
+	s.mu.Unlock()
+
+	if apiErr != nil {
+		writeJSON(w, apiErr.status, apiErr.body)
+		return
+	}
+
+	w.Header().Set("Content-Type", "audio/wav")
+	http.ServeContent(w, r, "recording.wav", time.Time{}, bytes.NewReader(data))
+}
+
+// expandCall returns a copy of the call with its agent expanded.
+func (s *Server) expandCall(obj object) object {
+	call := merge(obj, nil)
+	if id, ok := call["agent"].(string); ok {
+		if agent, ok := s.agents.get(id); ok {
+			call["agent"] = s.expandAgent(agent)
+		}
+	}
+	return call
+}
+
+// silentWAV returns 8kHz 16-bit mono PCM WAV of silence of duration d.
+func silentWAV(d time.Duration) []byte {
+	const rate, bits, channels = 8000, 16, 1
+	dataSize := uint32(d.Seconds() * rate * bits / 8 * channels)
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("RIFF")
+	_ = binary.Write(buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVEfmt ")
+	_ = binary.Write(buf, binary.LittleEndian, uint32(16))
+	_ = binary.Write(buf, binary.LittleEndian, uint16(1))
+	_ = binary.Write(buf, binary.LittleEndian, uint16(channels))
+	_ = binary.Write(buf, binary.LittleEndian, uint32(rate))
+	_ = binary.Write(buf, binary.LittleEndian, uint32(rate*bits/8*channels))
+	_ = binary.Write(buf, binary.LittleEndian, uint16(bits/8*channels))
+	_ = binary.Write(buf, binary.LittleEndian, uint16(bits))
+	buf.WriteString("data")
+	_ = binary.Write(buf, binary.LittleEndian, dataSize)
+	buf.Write(make([]byte, dataSize))
+
+	return buf.Bytes()
+}
@@ -0,0 +1,146 @@
+package vocodetest
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+const (
+	// DefaultPageSize is the default list page size.
+	DefaultPageSize = 10
+	// MaxPageSize is the maximum list page size.
+	MaxPageSize = 100
+)
+
+// object is a JSON object of the stored resource.
+type object = map[string]any
+
+// collection stores the resources of the same kind in their creation order.
+type collection struct {
+	kind  string
+	ids   []string
+	items map[string]object
+}
+
+func newCollection(kind string) *collection {
+	return &collection{
+		kind:  kind,
+		items: make(map[string]object),
+	}
+}
+
+func (c *collection) get(id string) (object, bool) {
+	obj, ok := c.items[id]
+	return obj, ok
+}
+
+func (c *collection) put(id string, obj object) {
+	if _, ok := c.items[id]; !ok {
+		c.ids = append(c.ids, id)
+	}
+	c.items[id] = obj
+}
+
+func (c *collection) delete(id string) bool {
+	if _, ok := c.items[id]; !ok {
+		return false
+	}
+	delete(c.items, id)
+	for i := range c.ids {
+		if c.ids[i] == id {
+			c.ids = append(c.ids[:i], c.ids[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (c *collection) list() []object {
+	objs := make([]object, 0, len(c.ids))
+	for _, id := range c.ids {
+		objs = append(objs, c.items[id])
+	}
+	return objs
+}
+
+// page is a single page of the listed resources.
+type page struct {
+	Items       []object `json:"items"`
+	Page        int      `json:"page"`
+	Size        int      `json:"size"`
+	Total       int      `json:"total"`
+	HasMore     bool     `json:"has_more"`
+	IsEstimated bool     `json:"total_is_estimated"`
+}
+
+// paginate returns the page of objs requested by the query paging params.
+func paginate(objs []object, q url.Values) (*page, *apiError) {
+	pageNr, size := 1, DefaultPageSize
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, paramError([]any{"query", "page"}, "Input should be greater than or equal to 1", "greater_than_equal", v)
+		}
+		pageNr = n
+	}
+	if v := q.Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxPageSize {
+			msg := fmt.Sprintf("Input should be between 1 and %d", MaxPageSize)
+			return nil, paramError([]any{"query", "size"}, msg, "less_than_equal", v)
+		}
+		size = n
+	}
+
+	if col := q.Get("sort_column"); col != "" {
+		desc, _ := strconv.ParseBool(q.Get("sort_desc"))
+		sorted := make([]object, len(objs))
+		copy(sorted, objs)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if desc {
+				return less(sorted[j][col], sorted[i][col])
+			}
+			return less(sorted[i][col], sorted[j][col])
+		})
+		objs = sorted
+	}
+
+	start := (pageNr - 1) * size
+	if start > len(objs) {
+		start = len(objs)
+	}
+	end := start + size
+	if end > len(objs) {
+		end = len(objs)
+	}
+
+	return &page{
+		Items:   objs[start:end],
+		Page:    pageNr,
+		Size:    size,
+		Total:   len(objs),
+		HasMore: end < len(objs),
+	}, nil
+}
+
+// less compares two JSON values of the same kind.
+// Values of different kinds are compared by their string form.
+func less(a, b any) bool {
+	switch av := a.(type) {
+	case string:
+		if bv, ok := b.(string); ok {
+			return av < bv
+		}
+	case float64:
+		if bv, ok := b.(float64); ok {
+			return av < bv
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			return !av && bv
+		}
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
@@ -0,0 +1,405 @@
+package vocodetest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/webhook"
+)
+
+// CallScript scripts the lifecycle of the simulated call.
+// Calls start as CallNotStarted, then move to CallInProgress,
+// go through all the Stages one step at a time and finally
+// finish with Status, Outcome and HumanDetection result.
+// Calls whose Outcome is CallStageDidNotConnect skip
+// the CallInProgress status altogether.
+type CallScript struct {
+	// Stages the call goes through while it is in progress.
+	// It defaults to a single CallPickedUp stage.
+	Stages []vocode.CallStage
+	// Status is the final call status.
+	// It defaults to CallEnded.
+	Status vocode.CallStatus
+	// Outcome is the final call stage outcome.
+	Outcome vocode.CallStageOutcome
+	// HumanDetection is the human detection result
+	// which becomes available when the call is picked up.
+	HumanDetection vocode.CallHumanDetection
+	// DNC is the do not call detection result.
+	DNC bool
+	// ErrorMsg is the error message of the CallError calls.
+	ErrorMsg string
+	// Transcript lines become available one per each in progress
+	// step; the remaining ones are added when the call finishes.
+	Transcript []string
+	// Recording becomes available when the call finishes.
+	// If it is nil and NoRecording is false, a silent WAV is used.
+	Recording []byte
+	// NoRecording disables the call recording.
+	NoRecording bool
+	// Duration of the call which is added to the usage minutes.
+	Duration time.Duration
+}
+
+// DefaultCallScript returns the script of the call that
+// gets picked up by a human and ends after a short chat.
+func DefaultCallScript() CallScript {
+	return CallScript{
+		Stages:         []vocode.CallStage{vocode.CallPickedUp},
+		Status:         vocode.CallEnded,
+		Outcome:        vocode.CallStageHumanDisconnect,
+		HumanDetection: vocode.CallHumanDetected,
+		Transcript: []string{
+			"BOT: Hello, how can I help you today?",
+			"HUMAN: I am just testing.",
+			"BOT: Thanks for calling, goodbye!",
+		},
+		Duration: time.Minute,
+	}
+}
+
+// Delivery records the webhook event delivery attempt.
+type Delivery struct {
+	Event      vocode.Event
+	CallID     string
+	URL        string
+	StatusCode int
+	Err        error
+}
+
+// lifecycle tracks the progress of the simulated call.
+type lifecycle struct {
+	script CallScript
+	stage  int
+	lines  int
+}
+
+// delivery is the pending webhook event delivery.
+type delivery struct {
+	hook  object
+	event webhook.Event
+}
+
+// WithAutoAdvance makes the server advance the
+// calls one lifecycle step every time they're fetched.
+func WithAutoAdvance() Option {
+	return func(o *Options) {
+		o.AutoAdvance = true
+	}
+}
+
+// SetDefaultCallScript sets the script of all
+// the calls that have no script set explicitly.
+func (s *Server) SetDefaultCallScript(script CallScript) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.defaultScript = script
+}
+
+// SetCallScript sets the script of the calls made to the phone number.
+// The script applies to the calls created after it has been set.
+func (s *Server) SetCallScript(toNumber string, script CallScript) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scripts[toNumber] = script
+}
+
+// AdvanceCall advances the call one lifecycle step
+// and fires the webhook events of the transition.
+func (s *Server) AdvanceCall(id string) (*vocode.Call, error) {
+	s.mu.Lock()
+	obj, ok := s.calls.get(id)
+	if !ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("call %s not found", id)
+	}
+	s.advance(obj)
+	call, err := s.toCall(obj)
+	deliveries := s.takeDeliveries()
+	s.mu.Unlock()
+
+	s.deliver(deliveries)
+
+	return call, err
+}
+
+// CompleteCall advances the call until it reaches its final status.
+func (s *Server) CompleteCall(id string) (*vocode.Call, error) {
+	for {
+		call, err := s.AdvanceCall(id)
+		if err != nil {
+			return nil, err
+		}
+		if call.Status == vocode.CallEnded || call.Status == vocode.CallError {
+			return call, nil
+		}
+	}
+}
+
+// Deliveries returns all the webhook event delivery attempts.
+func (s *Server) Deliveries() []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deliveries := make([]Delivery, len(s.deliveryLog))
+	copy(deliveries, s.deliveryLog)
+	return deliveries
+}
+
+// startLifecycle starts tracking the lifecycle of the new call.
+func (s *Server) startLifecycle(obj object) {
+	script, ok := s.scripts[obj["to_number"].(string)]
+	if !ok {
+		script = s.defaultScript
+	}
+	if len(script.Stages) == 0 {
+		script.Stages = []vocode.CallStage{vocode.CallPickedUp}
+	}
+	if script.Status == "" {
+		script.Status = vocode.CallEnded
+	}
+	s.lifecycles[obj["id"].(string)] = &lifecycle{script: script}
+}
+
+// advance moves the call one step forward in its lifecycle.
+func (s *Server) advance(obj object) {
+	lc, ok := s.lifecycles[obj["id"].(string)]
+	if !ok {
+		return
+	}
+
+	switch vocode.CallStatus(obj["status"].(string)) {
+	case vocode.CallNotStarted:
+		if lc.script.Outcome == vocode.CallStageDidNotConnect {
+			s.finish(obj, lc)
+			return
+		}
+		obj["status"] = string(vocode.CallInProgress)
+		obj["stage"] = string(lc.script.Stages[0])
+		obj["start_time"] = time.Now().UTC().Format(time.RFC3339)
+		s.fire(obj, vocode.CallConnectedEvent)
+		if lc.script.HumanDetection != "" {
+			obj["human_detection_result"] = string(lc.script.HumanDetection)
+			s.fire(obj, vocode.HumanDetectionEvent)
+		}
+		s.reveal(obj, lc, lc.lines+1)
+	case vocode.CallInProgress:
+		if lc.stage+1 < len(lc.script.Stages) {
+			lc.stage++
+			obj["stage"] = string(lc.script.Stages[lc.stage])
+			s.reveal(obj, lc, lc.lines+1)
+			return
+		}
+		s.finish(obj, lc)
+	}
+}
+
+// finish moves the call to its final status.
+func (s *Server) finish(obj object, lc *lifecycle) {
+	connected := obj["status"] == string(vocode.CallInProgress)
+	if connected {
+		s.reveal(obj, lc, len(lc.script.Transcript))
+	}
+
+	obj["status"] = string(lc.script.Status)
+	obj["end_time"] = time.Now().UTC().Format(time.RFC3339)
+	obj["do_not_call_result"] = lc.script.DNC
+	if lc.script.Outcome != "" {
+		obj["stage_outcome"] = string(lc.script.Outcome)
+	}
+	if lc.script.ErrorMsg != "" {
+		obj["error_message"] = lc.script.ErrorMsg
+	}
+
+	if !connected {
+		s.fire(obj, vocode.CallDidntConnectEvent)
+		delete(s.lifecycles, obj["id"].(string))
+		return
+	}
+
+	s.fire(obj, vocode.CallEndedEvent)
+	if lc.lines > 0 {
+		s.fire(obj, vocode.TranscriptEvent)
+	}
+	if !lc.script.NoRecording {
+		if lc.script.Recording != nil {
+			s.recordings[obj["id"].(string)] = lc.script.Recording
+		}
+		obj["recording_available"] = true
+		s.fire(obj, vocode.RecordingEvent)
+	}
+	s.usageMinutes += int(math.Ceil(lc.script.Duration.Minutes()))
+	delete(s.lifecycles, obj["id"].(string))
+}
+
+// reveal makes the first n transcript lines available
+// and fires the message events for the newly revealed ones.
+func (s *Server) reveal(obj object, lc *lifecycle, n int) {
+	if n > len(lc.script.Transcript) {
+		n = len(lc.script.Transcript)
+	}
+	for ; lc.lines < n; lc.lines++ {
+		line := lc.script.Transcript[lc.lines]
+		obj["transcript"] = strings.Join(lc.script.Transcript[:lc.lines+1], "\n")
+		call, err := s.toCall(obj)
+		if err != nil {
+			continue
+		}
+		sender, text, _ := strings.Cut(line, ":")
+		s.queue(obj, &webhook.MessageEvent{
+			Call:   *call,
+			Sender: strings.ToLower(strings.TrimSpace(sender)),
+			Text:   strings.TrimSpace(text),
+		})
+	}
+}
+
+// fire queues the webhook event of the given type for the call.
+func (s *Server) fire(obj object, typ vocode.Event) {
+	call, err := s.toCall(obj)
+	if err != nil {
+		return
+	}
+
+	var e webhook.Event
+	switch typ {
+	case vocode.CallConnectedEvent:
+		e = &webhook.CallConnectedEvent{Call: *call}
+	case vocode.CallEndedEvent:
+		e = &webhook.CallEndedEvent{Call: *call}
+	case vocode.CallDidntConnectEvent:
+		e = &webhook.CallDidntConnectEvent{Call: *call}
+	case vocode.TranscriptEvent:
+		e = &webhook.TranscriptEvent{Call: *call}
+	case vocode.RecordingEvent:
+		e = &webhook.RecordingEvent{Call: *call}
+	case vocode.HumanDetectionEvent:
+		e = &webhook.HumanDetectionEvent{Call: *call}
+	default:
+		return
+	}
+	s.queue(obj, e)
+}
+
+// queue queues the event delivery to the webhook of the call agent
+// if the webhook subscribes to the event. Queued events are delivered
+// once the server mutex has been released.
+func (s *Server) queue(obj object, e webhook.Event) {
+	agentID, _ := obj["agent"].(string)
+	agent, ok := s.agents.get(agentID)
+	if !ok {
+		return
+	}
+	hookID, _ := agent["webhook"].(string)
+	hook, ok := s.webhooks.get(hookID)
+	if !ok {
+		return
+	}
+	subs, _ := hook["subscriptions"].([]any)
+	for _, sub := range subs {
+		if sub == string(e.EventType()) {
+			s.pending = append(s.pending, delivery{hook: hook, event: e})
+			return
+		}
+	}
+}
+
+func (s *Server) takeDeliveries() []delivery {
+	pending := s.pending
+	s.pending = nil
+	return pending
+}
+
+// deliver sends the events to their webhooks.
+// It must be called without the server mutex held.
+func (s *Server) deliver(deliveries []delivery) {
+	for _, d := range deliveries {
+		rawURL, _ := d.hook["url"].(string)
+		method, _ := d.hook["method"].(string)
+		log := Delivery{
+			Event:  d.event.EventType(),
+			CallID: d.event.EventCall().ID,
+			URL:    rawURL,
+		}
+		log.StatusCode, log.Err = send(rawURL, method, d.event)
+
+		s.mu.Lock()
+		s.deliveryLog = append(s.deliveryLog, log)
+		s.mu.Unlock()
+	}
+}
+
+// send sends the webhook event to the URL using the given method.
+// GetWebhook events are encoded in the URL query parameters.
+func send(rawURL, method string, e webhook.Event) (int, error) {
+	data, err := webhook.Encode(e)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var req *http.Request
+	if method == string(vocode.GetWebhook) {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return 0, err
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return 0, err
+		}
+		q := u.Query()
+		for k, v := range fields {
+			var s string
+			if err := json.Unmarshal(v, &s); err == nil {
+				q.Set(k, s)
+				continue
+			}
+			q.Set(k, string(v))
+		}
+		u.RawQuery = q.Encode()
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(data))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// toCall converts the call object to vocode.Call.
+func (s *Server) toCall(obj object) (*vocode.Call, error) {
+	data, err := json.Marshal(s.expandCall(obj))
+	if err != nil {
+		return nil, err
+	}
+	call := new(vocode.Call)
+	if err := json.Unmarshal(data, call); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
@@ -0,0 +1,191 @@
+package vocodetest
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/webhook"
+)
+
+// eventSink is a webhook receiver which records the received events.
+type eventSink struct {
+	mu     sync.Mutex
+	events []webhook.Event
+}
+
+func (s *eventSink) handle(_ context.Context, e webhook.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *eventSink) types() []vocode.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	types := make([]vocode.Event, 0, len(s.events))
+	for _, e := range s.events {
+		types = append(types, e.EventType())
+	}
+	return types
+}
+
+// setupWebhookAgent creates an agent whose webhook delivers all the events
+// to the returned sink using the given method and returns the agent number.
+func setupWebhookAgent(t *testing.T, c *vocode.Client, method vocode.WebhookMethod) (*vocode.Number, *eventSink) {
+	t.Helper()
+	ctx := context.Background()
+
+	sink := &eventSink{}
+	receiver := httptest.NewServer(webhook.NewHandler(sink.handle))
+	t.Cleanup(receiver.Close)
+
+	hook, err := c.CreateWebhook(ctx, &vocode.CreateWebhookReq{
+		WebhookReq: vocode.WebhookReq{
+			URL:    receiver.URL,
+			Method: method,
+			Subs: []vocode.Event{
+				vocode.MessageEvent,
+				vocode.CallConnectedEvent,
+				vocode.CallEndedEvent,
+				vocode.CallDidntConnectEvent,
+				vocode.TranscriptEvent,
+				vocode.RecordingEvent,
+				vocode.HumanDetectionEvent,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agent, number := setupAgent(t, c)
+	_, err = c.UpdateAgent(ctx, agent.ID, &vocode.UpdateAgentReq{
+		AgentReq: vocode.AgentReq{Name: agent.Name, Prompt: agent.Prompt.ID, Voice: agent.Voice.ID, Webhook: hook.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return number, sink
+}
+
+func TestCallLifecycle(t *testing.T) {
+	t.Parallel()
+	t.Run("scripted", func(t *testing.T) {
+		t.Parallel()
+		s := NewServer()
+		defer s.Close()
+		c := s.Client()
+		ctx := context.Background()
+
+		number, sink := setupWebhookAgent(t, c, vocode.PostWebhook)
+		s.SetCallScript("+15550100", CallScript{
+			Stages:         []vocode.CallStage{vocode.CallPickedUp, vocode.CallTransferStart, vocode.CallTransferSuccess},
+			Outcome:        vocode.CallStageTransferDisconnect,
+			HumanDetection: vocode.CallHumanDetected,
+			Transcript:     []string{"BOT: Hi", "HUMAN: Transfer me"},
+		})
+
+		call, err := c.CreateCall(ctx, &vocode.CreateCallReq{FromNr: number.Number, ToNr: "+15550100", Agent: number.InboundAgent.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var stages []vocode.CallStage
+		for call.Status != vocode.CallEnded {
+			call, err = s.AdvanceCall(call.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stages = append(stages, call.Stage)
+		}
+
+		expStages := []vocode.CallStage{vocode.CallPickedUp, vocode.CallTransferStart, vocode.CallTransferSuccess, vocode.CallTransferSuccess}
+		if !reflect.DeepEqual(stages, expStages) {
+			t.Fatalf("expected stages: %v, got: %v", expStages, stages)
+		}
+		if call.StageOutcome != vocode.CallStageTransferDisconnect || call.HumanDetection != vocode.CallHumanDetected {
+			t.Fatalf("unexpected call outcome: %+v", call)
+		}
+		if call.Transcript != "BOT: Hi\nHUMAN: Transfer me" || !call.RecordAvailable {
+			t.Fatalf("unexpected call transcript or recording: %+v", call)
+		}
+
+		expEvents := []vocode.Event{
+			vocode.CallConnectedEvent,
+			vocode.HumanDetectionEvent,
+			vocode.MessageEvent,
+			vocode.MessageEvent,
+			vocode.CallEndedEvent,
+			vocode.TranscriptEvent,
+			vocode.RecordingEvent,
+		}
+		if got := sink.types(); !reflect.DeepEqual(got, expEvents) {
+			t.Fatalf("expected events: %v, got: %v", expEvents, got)
+		}
+		for _, d := range s.Deliveries() {
+			if d.Err != nil || d.StatusCode != 200 {
+				t.Fatalf("unexpected delivery: %+v", d)
+			}
+		}
+	})
+	t.Run("did_not_connect", func(t *testing.T) {
+		t.Parallel()
+		s := NewServer()
+		defer s.Close()
+		c := s.Client()
+		ctx := context.Background()
+
+		number, sink := setupWebhookAgent(t, c, vocode.GetWebhook)
+		s.SetDefaultCallScript(CallScript{Outcome: vocode.CallStageDidNotConnect})
+
+		call, err := c.CreateCall(ctx, &vocode.CreateCallReq{FromNr: number.Number, ToNr: "+15550111", Agent: number.InboundAgent.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		call, err = s.CompleteCall(call.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if call.StageOutcome != vocode.CallStageDidNotConnect || call.RecordAvailable {
+			t.Fatalf("unexpected call: %+v", call)
+		}
+		if got := sink.types(); !reflect.DeepEqual(got, []vocode.Event{vocode.CallDidntConnectEvent}) {
+			t.Fatalf("unexpected events: %v", got)
+		}
+	})
+	t.Run("auto_advance", func(t *testing.T) {
+		t.Parallel()
+		s := NewServer(WithAutoAdvance())
+		defer s.Close()
+		c := s.Client()
+		ctx := context.Background()
+
+		agent, number := setupAgent(t, c)
+		call, err := c.CreateCall(ctx, &vocode.CreateCallReq{FromNr: number.Number, ToNr: "+15550122", Agent: agent.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 10 && call.Status != vocode.CallEnded; i++ {
+			call, err = c.GetCall(ctx, call.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if call.Status != vocode.CallEnded {
+			t.Fatalf("expected call status: %s, got: %s", vocode.CallEnded, call.Status)
+		}
+		usage, err := c.GetUsage(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if usage.MonthlyMinutes != 1 {
+			t.Fatalf("expected usage minutes: %d, got: %d", 1, usage.MonthlyMinutes)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package vocodetest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+var areaCodeRe = regexp.MustCompile(`^[0-9]{3}$`)
+
+func (s *Server) listNumbers(r *http.Request) (any, *apiError) {
+	p, apiErr := paginate(s.numbers.list(), r.URL.Query())
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	items := make([]object, 0, len(p.Items))
+	for _, obj := range p.Items {
+		items = append(items, s.expandNumber(obj))
+	}
+	p.Items = items
+	return p, nil
+}
+
+func (s *Server) getNumber(r *http.Request) (any, *apiError) {
+	obj, apiErr := lookup(s.numbers, r, "phone_number")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return s.expandNumber(obj), nil
+}
+
+func (s *Server) buyNumber(r *http.Request) (any, *apiError) {
+	body, apiErr := decodeBody(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if apiErr := requireString(body, "area_code"); apiErr != nil {
+		return nil, apiErr
+	}
+	areaCode := body["area_code"].(string)
+	if !areaCodeRe.MatchString(areaCode) {
+		return nil, paramError([]any{"body", "area_code"}, "String should match pattern '^[0-9]{3}$'", "string_pattern_mismatch", areaCode)
+	}
+	if body["telephony_provider"] == nil || body["telephony_provider"] == "" {
+		body["telephony_provider"] = string(vocode.TwilioTelProvider)
+	}
+	if apiErr := requireOneOf(body, "telephony_provider", vocode.TwilioTelProvider, vocode.VonageTelProvider); apiErr != nil {
+		return nil, apiErr
+	}
+	if apiErr := resolveRef(body, "telephony_account_connection", s.accountConns, false); apiErr != nil {
+		return nil, apiErr
+	}
+
+	number := newNumber(areaCode)
+	for _, ok := s.numbers.get(number); ok; _, ok = s.numbers.get(number) {
+		number = newNumber(areaCode)
+	}
+
+	obj := object{
+		"id":                           newID(),
+		"user_id":                      s.opts.UserID,
+		"active":                       true,
+		"label":                        "",
+		"inbound_agent":                nil,
+		"outbound_only":                false,
+		"example_context":              nil,
+		"number":                       number,
+		"telephony_provider":           body["telephony_provider"],
+		"telephony_account_connection": body["telephony_account_connection"],
+	}
+	s.numbers.put(number, obj)
+
+	return s.expandNumber(obj), nil
+}
+
+func (s *Server) updateNumber(r *http.Request) (any, *apiError) {
+	existing, apiErr := lookup(s.numbers, r, "phone_number")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	body, apiErr := decodeBody(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	obj := merge(existing, nil)
+	for _, field := range []string{"label", "outbound_only", "inbound_agent", "example_context"} {
+		if v, ok := body[field]; ok {
+			obj[field] = v
+		}
+	}
+	if apiErr := resolveRef(obj, "inbound_agent", s.agents, false); apiErr != nil {
+		return nil, apiErr
+	}
+	s.numbers.put(obj["number"].(string), obj)
+
+	return s.expandNumber(obj), nil
+}
+
+func (s *Server) cancelNumber(r *http.Request) (any, *apiError) {
+	obj, apiErr := lookup(s.numbers, r, "phone_number")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	s.numbers.delete(obj["number"].(string))
+
+	number := s.expandNumber(obj)
+	number["active"] = false
+
+	return number, nil
+}
+
+// expandNumber returns a copy of the number with its inbound agent expanded.
+func (s *Server) expandNumber(obj object) object {
+	number := merge(obj, nil)
+	if id, ok := number["inbound_agent"].(string); ok {
+		if agent, ok := s.agents.get(id); ok {
+			number["inbound_agent"] = s.expandAgent(agent)
+		}
+	}
+	return number
+}
+
+// newNumber returns a new random US phone number with the area code.
+func newNumber(areaCode string) string {
+	n, err := rand.Int(rand.Reader, big.NewInt(10_000_000))
+	if err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("+1%s%07d", areaCode, n.Int64())
+}
@@ -0,0 +1,355 @@
+package vocodetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// resource is an API resource served via the generic
+// list, get, create, update and delete endpoints.
+type resource struct {
+	path     string
+	col      *collection
+	validate func(obj object) *apiError
+	expand   func(obj object) object
+}
+
+func (s *Server) resources() []*resource {
+	return []*resource{
+		{path: "prompts", col: s.prompts, validate: validatePrompt},
+		{path: "voices", col: s.voices, validate: validateVoice},
+		{path: "actions", col: s.actions, validate: validateAction},
+		{path: "webhooks", col: s.webhooks, validate: validateWebhook},
+		{path: "vector_databases", col: s.vectorDBs, validate: validateVectorDB},
+		{path: "account_connections", col: s.accountConns, validate: validateAccountConn},
+		{path: "agents", col: s.agents, validate: s.validateAgent, expand: s.expandAgent},
+	}
+}
+
+func (res *resource) expandObj(obj object) object {
+	if res.expand == nil {
+		return obj
+	}
+	return res.expand(obj)
+}
+
+func (s *Server) createResource(res *resource) handlerFunc {
+	return func(r *http.Request) (any, *apiError) {
+		obj, apiErr := decodeBody(r)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		if apiErr := res.validate(obj); apiErr != nil {
+			return nil, apiErr
+		}
+		obj["id"] = newID()
+		obj["user_id"] = s.opts.UserID
+		res.col.put(obj["id"].(string), obj)
+		return res.expandObj(obj), nil
+	}
+}
+
+func (res *resource) list(r *http.Request) (any, *apiError) {
+	p, apiErr := paginate(res.col.list(), r.URL.Query())
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	items := make([]object, 0, len(p.Items))
+	for _, obj := range p.Items {
+		items = append(items, res.expandObj(obj))
+	}
+	p.Items = items
+	return p, nil
+}
+
+func (res *resource) get(r *http.Request) (any, *apiError) {
+	obj, apiErr := lookup(res.col, r, "id")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return res.expandObj(obj), nil
+}
+
+func (res *resource) update(r *http.Request) (any, *apiError) {
+	existing, apiErr := lookup(res.col, r, "id")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	body, apiErr := decodeBody(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	obj := merge(existing, body)
+	if apiErr := res.validate(obj); apiErr != nil {
+		return nil, apiErr
+	}
+	res.col.put(obj["id"].(string), obj)
+	return res.expandObj(obj), nil
+}
+
+func (res *resource) delete(r *http.Request) (any, *apiError) {
+	obj, apiErr := lookup(res.col, r, "id")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	res.col.delete(obj["id"].(string))
+	return res.expandObj(obj), nil
+}
+
+// lookup returns the object stored in col under the ID
+// passed in the query parameter param of the request.
+func lookup(col *collection, r *http.Request, param string) (object, *apiError) {
+	id := r.URL.Query().Get(param)
+	if id == "" {
+		return nil, paramError([]any{"query", param}, "Field required", "missing", nil)
+	}
+	obj, ok := col.get(id)
+	if !ok {
+		return nil, notFound(col.kind)
+	}
+	return obj, nil
+}
+
+func decodeBody(r *http.Request) (object, *apiError) {
+	var obj object
+	if err := json.NewDecoder(r.Body).Decode(&obj); err != nil {
+		return nil, paramError([]any{"body"}, "JSON decode error", "json_invalid", err.Error())
+	}
+	if obj == nil {
+		return nil, paramError([]any{"body"}, "Field required", "missing", nil)
+	}
+	return obj, nil
+}
+
+// merge returns a copy of dst with all src fields set on it.
+func merge(dst, src object) object {
+	obj := make(object, len(dst)+len(src))
+	for k, v := range dst {
+		obj[k] = v
+	}
+	for k, v := range src {
+		obj[k] = v
+	}
+	return obj
+}
+
+func requireString(obj object, field string) *apiError {
+	v, ok := obj[field]
+	if !ok || v == nil {
+		return paramError([]any{"body", field}, "Field required", "missing", nil)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return paramError([]any{"body", field}, "Input should be a valid string", "string_type", nil)
+	}
+	if s == "" {
+		return paramError([]any{"body", field}, "String should have at least 1 character", "string_too_short", s)
+	}
+	return nil
+}
+
+func requireOneOf[T ~string](obj object, field string, allowed ...T) *apiError {
+	if apiErr := requireString(obj, field); apiErr != nil {
+		return apiErr
+	}
+	v := obj[field].(string)
+	for _, a := range allowed {
+		if v == string(a) {
+			return nil
+		}
+	}
+	return paramError([]any{"body", field}, fmt.Sprintf("Input should be one of %v", allowed), "enum", v)
+}
+
+// resolveRef normalizes the reference in obj field to the referenced
+// resource ID. References can be either the ID or an object with the ID.
+// It returns the not found error if the referenced resource does not exist.
+func resolveRef(obj object, field string, col *collection, required bool) *apiError {
+	v, ok := obj[field]
+	if !ok || v == nil || v == "" {
+		if required {
+			return paramError([]any{"body", field}, "Field required", "missing", nil)
+		}
+		obj[field] = nil
+		return nil
+	}
+	id, apiErr := refID(v, field)
+	if apiErr != nil {
+		return apiErr
+	}
+	if _, ok := col.get(id); !ok {
+		return notFound(col.kind)
+	}
+	obj[field] = id
+	return nil
+}
+
+func refID(v any, loc ...any) (string, *apiError) {
+	switch ref := v.(type) {
+	case string:
+		return ref, nil
+	case map[string]any:
+		if id, ok := ref["id"].(string); ok && id != "" {
+			return id, nil
+		}
+	}
+	return "", paramError(append([]any{"body"}, loc...), "Input should be a valid UUID", "uuid_type", nil)
+}
+
+func validatePrompt(obj object) *apiError {
+	if apiErr := requireString(obj, "content"); apiErr != nil {
+		return apiErr
+	}
+	if obj["collect_fields"] == nil {
+		obj["collect_fields"] = []any{}
+	}
+	return nil
+}
+
+func validateVoice(obj object) *apiError {
+	return requireOneOf(obj, "type",
+		vocode.AzureVoiceType,
+		vocode.RimeVoiceType,
+		vocode.ElevenLabsVoiceType,
+		vocode.PlayHtVoiceType,
+	)
+}
+
+func validateAction(obj object) *apiError {
+	apiErr := requireOneOf(obj, "type",
+		vocode.ActionDTMF,
+		vocode.ActionSetHold,
+		vocode.ActionExternal,
+		vocode.ActionTransferCall,
+		vocode.ActionEndConversation,
+		vocode.ActionAddToConference,
+	)
+	if apiErr != nil {
+		return apiErr
+	}
+	trigger, ok := obj["action_trigger"].(map[string]any)
+	if !ok {
+		return paramError([]any{"body", "action_trigger"}, "Field required", "missing", nil)
+	}
+	switch t, _ := trigger["type"].(string); vocode.TriggerType(t) {
+	case vocode.FnCallTriggerType, vocode.PhraseTriggerType:
+	default:
+		return paramError([]any{"body", "action_trigger", "type"}, "Input should be a valid action trigger type", "enum", t)
+	}
+	if obj["config"] == nil {
+		obj["config"] = map[string]any{}
+	}
+	return nil
+}
+
+func validateWebhook(obj object) *apiError {
+	if apiErr := requireString(obj, "url"); apiErr != nil {
+		return apiErr
+	}
+	if obj["method"] == nil || obj["method"] == "" {
+		obj["method"] = string(vocode.PostWebhook)
+	}
+	if apiErr := requireOneOf(obj, "method", vocode.GetWebhook, vocode.PostWebhook); apiErr != nil {
+		return apiErr
+	}
+	subs, _ := obj["subscriptions"].([]any)
+	for i, sub := range subs {
+		s, _ := sub.(string)
+		switch vocode.Event(s) {
+		case vocode.MessageEvent, vocode.ActionEvent, vocode.CallConnectedEvent,
+			vocode.CallEndedEvent, vocode.CallDidntConnectEvent, vocode.TranscriptEvent,
+			vocode.RecordingEvent, vocode.HumanDetectionEvent:
+		default:
+			return paramError([]any{"body", "subscriptions", i}, "Input should be a valid event type", "enum", s)
+		}
+	}
+	if subs == nil {
+		obj["subscriptions"] = []any{}
+	}
+	return nil
+}
+
+func validateVectorDB(obj object) *apiError {
+	if apiErr := requireOneOf(obj, "type", vocode.PineConeVectorDB); apiErr != nil {
+		return apiErr
+	}
+	return requireString(obj, "index")
+}
+
+func validateAccountConn(obj object) *apiError {
+	if apiErr := requireOneOf(obj, "type", vocode.AccountConnOpenAI, vocode.AccountConnTwilio); apiErr != nil {
+		return apiErr
+	}
+	if _, ok := obj["credentials"].(map[string]any); !ok {
+		return paramError([]any{"body", "credentials"}, "Field required", "missing", nil)
+	}
+	return nil
+}
+
+func (s *Server) validateAgent(obj object) *apiError {
+	if err := resolveRef(obj, "prompt", s.prompts, true); err != nil {
+		return err
+	}
+	if err := resolveRef(obj, "voice", s.voices, true); err != nil {
+		return err
+	}
+	if err := resolveRef(obj, "webhook", s.webhooks, false); err != nil {
+		return err
+	}
+	if err := resolveRef(obj, "vector_database", s.vectorDBs, false); err != nil {
+		return err
+	}
+
+	actions, _ := obj["actions"].([]any)
+	ids := make([]any, 0, len(actions))
+	for i, a := range actions {
+		id, apiErr := refID(a, "actions", i)
+		if apiErr != nil {
+			return apiErr
+		}
+		if _, ok := s.actions.get(id); !ok {
+			return notFound(s.actions.kind)
+		}
+		ids = append(ids, id)
+	}
+	obj["actions"] = ids
+
+	if obj["language"] == nil || obj["language"] == "" {
+		obj["language"] = string(vocode.English)
+	}
+	return nil
+}
+
+// expandAgent returns a copy of the agent with
+// its references replaced by the referenced objects.
+func (s *Server) expandAgent(obj object) object {
+	agent := merge(obj, nil)
+	expandRef(agent, "prompt", s.prompts)
+	expandRef(agent, "voice", s.voices)
+	expandRef(agent, "webhook", s.webhooks)
+	expandRef(agent, "vector_database", s.vectorDBs)
+
+	ids, _ := obj["actions"].([]any)
+	actions := make([]any, 0, len(ids))
+	for _, id := range ids {
+		if a, ok := s.actions.get(fmt.Sprint(id)); ok {
+			actions = append(actions, a)
+		}
+	}
+	agent["actions"] = actions
+
+	return agent
+}
+
+func expandRef(obj object, field string, col *collection) {
+	id, ok := obj[field].(string)
+	if !ok {
+		return
+	}
+	if ref, ok := col.get(id); ok {
+		obj[field] = ref
+	}
+}
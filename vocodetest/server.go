@@ -0,0 +1,287 @@
+// Package vocodetest provides an in-memory fake Vocode API server for tests.
+package vocodetest
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+const (
+	// DefaultAPIKey is the default API key accepted by the Server.
+	DefaultAPIKey = "vocodetest-api-key"
+	// DefaultUserID is the default ID of the user owning the Server resources.
+	DefaultUserID = "vocodetest-user"
+	// DefaultMonthlyLimitMinutes is the default monthly usage limit.
+	DefaultMonthlyLimitMinutes = 1000
+)
+
+// Server is an in-memory fake Vocode API server.
+// It implements all the API endpoints the vocode.Client calls.
+// Its zero value is not usable; use NewServer to create it.
+type Server struct {
+	*httptest.Server
+	opts Options
+
+	mu           sync.Mutex
+	agents       *collection
+	prompts      *collection
+	voices       *collection
+	actions      *collection
+	webhooks     *collection
+	vectorDBs    *collection
+	accountConns *collection
+	calls        *collection
+	numbers      *collection
+	recordings   map[string][]byte
+	usageMinutes int
+
+	defaultScript CallScript
+	scripts       map[string]CallScript
+	lifecycles    map[string]*lifecycle
+	pending       []delivery
+	deliveryLog   []Delivery
+}
+
+// Options configure the Server.
+type Options struct {
+	APIKey              string
+	UserID              string
+	PlanType            vocode.PlanType
+	MonthlyLimitMinutes int
+	AutoAdvance         bool
+}
+
+// Option is functional server option.
+type Option func(*Options)
+
+// NewServer creates a new Server, starts it and returns it.
+// The caller should call Close when finished to shut it down.
+func NewServer(opts ...Option) *Server {
+	options := Options{
+		APIKey:              DefaultAPIKey,
+		UserID:              DefaultUserID,
+		PlanType:            vocode.PlanDeveloper,
+		MonthlyLimitMinutes: DefaultMonthlyLimitMinutes,
+	}
+	for _, apply := range opts {
+		apply(&options)
+	}
+
+	s := &Server{
+		opts:         options,
+		agents:       newCollection("Agent"),
+		prompts:      newCollection("Prompt"),
+		voices:       newCollection("Voice"),
+		actions:      newCollection("Action"),
+		webhooks:     newCollection("Webhook"),
+		vectorDBs:    newCollection("Vector database"),
+		accountConns: newCollection("Account connection"),
+		calls:        newCollection("Call"),
+		numbers:      newCollection("Phone number"),
+		recordings:   make(map[string][]byte),
+
+		defaultScript: DefaultCallScript(),
+		scripts:       make(map[string]CallScript),
+		lifecycles:    make(map[string]*lifecycle),
+	}
+	s.Server = httptest.NewServer(s.routes())
+
+	return s
+}
+
+// WithAPIKey sets the API key the server accepts.
+func WithAPIKey(apiKey string) Option {
+	return func(o *Options) {
+		o.APIKey = apiKey
+	}
+}
+
+// WithUserID sets the ID of the user owning the server resources.
+func WithUserID(userID string) Option {
+	return func(o *Options) {
+		o.UserID = userID
+	}
+}
+
+// WithPlan sets the usage plan type and its monthly limit.
+func WithPlan(plan vocode.PlanType, limitMinutes int) Option {
+	return func(o *Options) {
+		o.PlanType = plan
+		o.MonthlyLimitMinutes = limitMinutes
+	}
+}
+
+// Client returns a new vocode.Client configured to talk to the server.
+// The options are applied after the server ones so they can override them.
+func (s *Server) Client(opts ...vocode.Option) *vocode.Client {
+	options := []vocode.Option{
+		vocode.WithBaseURL(s.URL),
+		vocode.WithAPIKey(s.opts.APIKey),
+	}
+	return vocode.NewClient(append(options, opts...)...)
+}
+
+// SetUsage sets the monthly usage minutes reported by the server.
+func (s *Server) SetUsage(minutes int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.usageMinutes = minutes
+}
+
+// SetRecording sets the recording of the call and marks it as available.
+func (s *Server) SetRecording(callID string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	call, ok := s.calls.get(callID)
+	if !ok {
+		return fmt.Errorf("call %s not found", callID)
+	}
+	s.recordings[callID] = data
+	call["recording_available"] = true
+	return nil
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	prefix := "/" + vocode.APIV1
+
+	for _, r := range s.resources() {
+		r := r
+		base := prefix + "/" + r.path
+		mux.HandleFunc(base, s.handle(http.MethodGet, r.get))
+		mux.HandleFunc(base+"/list", s.handle(http.MethodGet, r.list))
+		mux.HandleFunc(base+"/create", s.handle(http.MethodPost, s.createResource(r)))
+		mux.HandleFunc(base+"/update", s.handle(http.MethodPost, r.update))
+		mux.HandleFunc(base+"/delete", s.handle(http.MethodPost, r.delete))
+	}
+
+	mux.HandleFunc(prefix+"/calls", s.handle(http.MethodGet, s.getCall))
+	mux.HandleFunc(prefix+"/calls/list", s.handle(http.MethodGet, s.listCalls))
+	mux.HandleFunc(prefix+"/calls/create", s.handle(http.MethodPost, s.createCall))
+	mux.HandleFunc(prefix+"/calls/end", s.handle(http.MethodPost, s.endCall))
+	mux.HandleFunc(prefix+"/calls/recording", s.auth(http.MethodGet, s.getRecording))
+
+	mux.HandleFunc(prefix+"/numbers", s.handle(http.MethodGet, s.getNumber))
+	mux.HandleFunc(prefix+"/numbers/list", s.handle(http.MethodGet, s.listNumbers))
+	mux.HandleFunc(prefix+"/numbers/buy", s.handle(http.MethodPost, s.buyNumber))
+	mux.HandleFunc(prefix+"/numbers/update", s.handle(http.MethodPost, s.updateNumber))
+	mux.HandleFunc(prefix+"/numbers/cancel", s.handle(http.MethodPost, s.cancelNumber))
+
+	mux.HandleFunc(prefix+"/usage", s.handle(http.MethodGet, s.getUsage))
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"detail": "Not Found"})
+	})
+
+	return mux
+}
+
+// handlerFunc handles the API request and returns the response
+// JSON value or the API error. Handlers are called with the
+// server mutex held, so they must not lock it again.
+type handlerFunc func(r *http.Request) (any, *apiError)
+
+// handle returns http.HandlerFunc which authenticates the request,
+// checks its method, calls fn and writes its response.
+// The webhook events fired by fn are delivered before
+// the response is written.
+func (s *Server) handle(method string, fn handlerFunc) http.HandlerFunc {
+	return s.auth(method, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		v, apiErr := fn(r)
+		deliveries := s.takeDeliveries()
+		s.mu.Unlock()
+
+		s.deliver(deliveries)
+
+		if apiErr != nil {
+			writeJSON(w, apiErr.status, apiErr.body)
+			return
+		}
+		writeJSON(w, http.StatusOK, v)
+	})
+}
+
+// auth returns http.HandlerFunc which authenticates
+// the request and checks its method before calling fn.
+func (s *Server) auth(method string, fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || token != s.opts.APIKey {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "Invalid API key"})
+			return
+		}
+		if r.Method != method {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"detail": "Method Not Allowed"})
+			return
+		}
+		fn(w, r)
+	}
+}
+
+func (s *Server) getUsage(*http.Request) (any, *apiError) {
+	return vocode.Usage{
+		UserID:              s.opts.UserID,
+		PlanType:            s.opts.PlanType,
+		MonthlyMinutes:      s.usageMinutes,
+		MonthlyLimitMinutes: s.opts.MonthlyLimitMinutes,
+	}, nil
+}
+
+// apiError is the API error response.
+type apiError struct {
+	status int
+	body   any
+}
+
+// paramError returns the validation API error for the parameter at loc.
+func paramError(loc []any, msg, typ string, input any) *apiError {
+	detail := map[string]any{
+		"type": typ,
+		"loc":  loc,
+		"msg":  msg,
+	}
+	if s, ok := input.(string); ok {
+		detail["input"] = s
+	}
+	return &apiError{
+		status: http.StatusUnprocessableEntity,
+		body:   map[string]any{"detail": []any{detail}},
+	}
+}
+
+// notFound returns the not found API error for the resource kind.
+func notFound(kind string) *apiError {
+	return &apiError{
+		status: http.StatusNotFound,
+		body:   map[string]string{"detail": kind + " not found"},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+}
+
+// newID returns a new random resource ID.
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
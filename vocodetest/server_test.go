@@ -0,0 +1,283 @@
+package vocodetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// setupAgent creates an agent with all its dependencies and
+// a phone number it answers and returns the agent and the number.
+func setupAgent(t *testing.T, c *vocode.Client) (*vocode.Agent, *vocode.Number) {
+	t.Helper()
+	ctx := context.Background()
+
+	prompt, err := c.CreatePrompt(ctx, &vocode.CreatePromptReq{
+		PromptReq: vocode.PromptReq{Content: "You are a helpful assistant."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	voice, err := c.CreateVoice(ctx, &vocode.CreateVoiceReq{
+		VoiceReq: vocode.VoiceReq{
+			Type:      vocode.RimeVoiceType,
+			RimeVoice: &vocode.RimeVoice{Speaker: "young_male", ModelID: vocode.MistRimeVoiceModel},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	action, err := c.CreateAction(ctx, &vocode.CreateActionReq{
+		ActionReq: vocode.ActionReq{
+			Type: vocode.ActionEndConversation,
+			Trigger: &vocode.PhraseTrigger{
+				Type: vocode.PhraseTriggerType,
+				Config: &vocode.PhraseTriggerConfig{
+					PhraseTriggers: []vocode.Phrase{{Phrase: "bye", Conditions: []vocode.PhraseCondition{vocode.PhraseCondTypeContains}}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent, err := c.CreateAgent(ctx, &vocode.CreateAgentReq{
+		AgentReq: vocode.AgentReq{
+			Name:    "test agent",
+			Prompt:  prompt.ID,
+			Voice:   voice.ID,
+			Actions: []string{action.ID},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	number, err := c.BuyNumber(ctx, &vocode.BuyNumberReq{AreaCode: "415", TelProvider: vocode.TwilioTelProvider})
+	if err != nil {
+		t.Fatal(err)
+	}
+	number, err = c.UpdateNumber(ctx, number.Number, &vocode.UpdateNumberReq{Label: "test", InboundAgent: agent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return agent, number
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	t.Run("resources", func(t *testing.T) {
+		t.Parallel()
+		s := NewServer()
+		defer s.Close()
+		c := s.Client()
+		ctx := context.Background()
+
+		agent, number := setupAgent(t, c)
+		if agent.Prompt == nil || agent.Prompt.Content == "" {
+			t.Fatalf("expected expanded prompt, got: %+v", agent.Prompt)
+		}
+		if agent.Voice == nil || agent.Voice.RimeVoice == nil || agent.Voice.Speaker != "young_male" {
+			t.Fatalf("expected expanded voice, got: %+v", agent.Voice)
+		}
+		if len(agent.Actions) != 1 || agent.Actions[0].Type != vocode.ActionEndConversation {
+			t.Fatalf("expected expanded actions, got: %+v", agent.Actions)
+		}
+		if number.InboundAgent == nil || number.InboundAgent.ID != agent.ID || number.Label != "test" {
+			t.Fatalf("unexpected number: %+v", number)
+		}
+
+		got, err := c.GetAgent(ctx, agent.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != agent.Name {
+			t.Fatalf("expected agent name: %s, got: %s", agent.Name, got.Name)
+		}
+
+		updated, err := c.UpdateAgent(ctx, agent.ID, &vocode.UpdateAgentReq{
+			AgentReq: vocode.AgentReq{Name: "renamed", Prompt: agent.Prompt.ID, Voice: agent.Voice.ID},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Name != "renamed" || len(updated.Actions) != 0 {
+			t.Fatalf("unexpected updated agent: %+v", updated)
+		}
+
+		cancelled, err := c.CancelNumber(ctx, number.Number)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cancelled.Active {
+			t.Fatal("expected cancelled number to be inactive")
+		}
+	})
+	t.Run("delete", func(t *testing.T) {
+		t.Parallel()
+		s := NewServer()
+		defer s.Close()
+		c := s.Client()
+		ctx := context.Background()
+
+		agent, _ := setupAgent(t, c)
+		if err := c.DeleteAgent(ctx, agent.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.GetAgent(ctx, agent.ID); !errors.Is(err, vocode.ErrNotFound) {
+			t.Fatalf("expected error: %v, got: %v", vocode.ErrNotFound, err)
+		}
+		if err := c.DeleteAgent(ctx, agent.ID); !errors.Is(err, vocode.ErrNotFound) {
+			t.Fatalf("expected error: %v, got: %v", vocode.ErrNotFound, err)
+		}
+
+		for _, del := range []struct {
+			id string
+			fn func(context.Context, string) error
+		}{
+			{id: agent.Prompt.ID, fn: c.DeletePrompt},
+			{id: agent.Voice.ID, fn: c.DeleteVoice},
+			{id: agent.Actions[0].ID, fn: c.DeleteAction},
+		} {
+			if err := del.fn(ctx, del.id); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, del := range []func(context.Context, string) error{
+			c.DeleteWebhook,
+			c.DeleteVectorDB,
+			c.DeleteAccountConn,
+		} {
+			if err := del(ctx, "missing"); !errors.Is(err, vocode.ErrNotFound) {
+				t.Fatalf("expected error: %v, got: %v", vocode.ErrNotFound, err)
+			}
+		}
+	})
+	t.Run("paging", func(t *testing.T) {
+		t.Parallel()
+		s := NewServer()
+		defer s.Close()
+		c := s.Client()
+		ctx := context.Background()
+
+		for i := 0; i < 5; i++ {
+			_, err := c.CreatePrompt(ctx, &vocode.CreatePromptReq{
+				PromptReq: vocode.PromptReq{Content: fmt.Sprintf("prompt %d", i)},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		prompts, err := c.ListPrompts(ctx, &vocode.PageParams{Page: 2, Size: 2, Sort: &vocode.Sort{Col: "content", Desc: true}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prompts.Total != 5 || !prompts.HasMore || len(prompts.Items) != 2 {
+			t.Fatalf("unexpected page: %+v", prompts.Paging)
+		}
+		if prompts.Items[0].Content != "prompt 2" || prompts.Items[1].Content != "prompt 1" {
+			t.Fatalf("unexpected page items: %+v", prompts.Items)
+		}
+
+		prompts, err = c.ListPrompts(ctx, &vocode.PageParams{Page: 3, Size: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prompts.HasMore || len(prompts.Items) != 1 {
+			t.Fatalf("unexpected last page: %+v", prompts.Paging)
+		}
+	})
+	t.Run("calls", func(t *testing.T) {
+		t.Parallel()
+		s := NewServer()
+		defer s.Close()
+		c := s.Client()
+		ctx := context.Background()
+
+		agent, number := setupAgent(t, c)
+		call, err := c.CreateCall(ctx, &vocode.CreateCallReq{
+			FromNr: number.Number,
+			ToNr:   "+15550100",
+			Agent:  agent.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if call.Status != vocode.CallNotStarted || call.Agent == nil || call.Agent.ID != agent.ID {
+			t.Fatalf("unexpected call: %+v", call)
+		}
+
+		var buf bytes.Buffer
+		if err := c.GetCallRecording(ctx, call.ID, &buf); err == nil {
+			t.Fatal("expected recording error")
+		}
+
+		call, err = c.EndCall(ctx, call.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if call.Status != vocode.CallEnded {
+			t.Fatalf("expected call status: %s, got: %s", vocode.CallEnded, call.Status)
+		}
+
+		recording := []byte("RIFF....WAVE")
+		if err := s.SetRecording(call.ID, recording); err != nil {
+			t.Fatal(err)
+		}
+		buf.Reset()
+		if err := c.GetCallRecording(ctx, call.ID, &buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), recording) {
+			t.Fatalf("expected recording: %q, got: %q", recording, buf.Bytes())
+		}
+
+		calls, err := c.ListCalls(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(calls.Items) != 1 || calls.Items[0].ID != call.ID {
+			t.Fatalf("unexpected calls: %+v", calls.Items)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		s := NewServer()
+		defer s.Close()
+		ctx := context.Background()
+
+		var apiErr *vocode.APIError
+		_, err := s.Client().GetAgent(ctx, "missing")
+		if !errors.As(err, &apiErr) || apiErr.GenError == nil {
+			t.Fatalf("expected generic API error, got: %v", err)
+		}
+
+		_, err = s.Client().CreatePrompt(ctx, &vocode.CreatePromptReq{})
+		if !errors.As(err, &apiErr) || apiErr.ParamError == nil {
+			t.Fatalf("expected param API error, got: %v", err)
+		}
+
+		_, err = s.Client(vocode.WithAPIKey("wrong")).GetUsage(ctx)
+		if !errors.As(err, &apiErr) || apiErr.GenError == nil {
+			t.Fatalf("expected auth API error, got: %v", err)
+		}
+	})
+	t.Run("usage", func(t *testing.T) {
+		t.Parallel()
+		s := NewServer(WithPlan(vocode.PlanFree, 10))
+		defer s.Close()
+		s.SetUsage(7)
+
+		usage, err := s.Client().GetUsage(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if usage.MonthlyMinutes != 7 || usage.MonthlyLimitMinutes != 10 || usage.PlanType != vocode.PlanFree {
+			t.Fatalf("unexpected usage: %+v", usage)
+		}
+	})
+}
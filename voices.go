@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 
@@ -180,6 +181,28 @@ func (v VoiceReq) MarshalJSON() ([]byte, error) {
 	}
 }
 
+func (v *VoiceReq) UnmarshalJSON(data []byte) error {
+	var voice Voice
+	if err := json.Unmarshal(data, &voice); err != nil {
+		return err
+	}
+
+	switch voice.Type {
+	case AzureVoiceType, RimeVoiceType, ElevenLabsVoiceType, PlayHtVoiceType:
+	default:
+		return fmt.Errorf("unsupported voice type: %s", voice.Type)
+	}
+
+	*v = VoiceReq{
+		Type:            voice.Type,
+		AzureVoice:      voice.AzureVoice,
+		RimeVoice:       voice.RimeVoice,
+		ElevenLabsVoice: voice.ElevenLabsVoice,
+		PlayHtVoice:     voice.PlayHtVoice,
+	}
+	return nil
+}
+
 type CreateVoiceReq struct {
 	VoiceReq
 }
@@ -202,11 +225,9 @@ func (c *Client) ListVoices(ctx context.Context, paging *PageParams) (*Voices, e
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
+	var options []request.HTTPOption
 	if paging != nil {
-		request.WithPageParams(paging.Encode())
+		options = append(options, request.WithPageParams(paging.Encode()))
 	}
 
 	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
@@ -214,7 +235,7 @@ func (c *Client) ListVoices(ctx context.Context, paging *PageParams) (*Voices, e
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -227,17 +248,24 @@ func (c *Client) ListVoices(ctx context.Context, paging *PageParams) (*Voices, e
 	return voices, nil
 }
 
+// AllVoices returns Pager which iterates over all the voices.
+func (c *Client) AllVoices(ctx context.Context, opts *ListOptions) *Pager[Voice] {
+	return newPager(ctx, opts, func(ctx context.Context, paging *PageParams) ([]Voice, *Paging, error) {
+		page, err := c.ListVoices(ctx, paging)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Items, page.Paging, nil
+	})
+}
+
 func (c *Client) GetVoice(ctx context.Context, id string) (*Voice, error) {
 	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/voices")
 	if err != nil {
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
+	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -245,7 +273,7 @@ func (c *Client) GetVoice(ctx context.Context, id string) (*Voice, error) {
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -271,16 +299,12 @@ func (c *Client) CreateVoice(ctx context.Context, createReq *CreateVoiceReq) (*V
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -306,11 +330,7 @@ func (c *Client) UpdateVoice(ctx context.Context, id string, updateReq *UpdateVo
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -318,7 +338,7 @@ func (c *Client) UpdateVoice(ctx context.Context, id string, updateReq *UpdateVo
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -330,3 +350,29 @@ func (c *Client) UpdateVoice(ctx context.Context, id string, updateReq *UpdateVo
 	}
 	return voice, nil
 }
+
+// DeleteVoice deletes the voice with the given id.
+// It returns error matching ErrNotFound if the voice does not exist.
+func (c *Client) DeleteVoice(ctx context.Context, id string) error {
+	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/voices/delete")
+	if err != nil {
+		return err
+	}
+
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	q.Add("id", id)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
@@ -0,0 +1,116 @@
+package vocode
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultWaitInterval is the default initial call polling interval.
+	DefaultWaitInterval = time.Second
+	// DefaultWaitMaxInterval is the default maximum call polling interval.
+	DefaultWaitMaxInterval = 10 * time.Second
+)
+
+// Clock provides the current time and timers.
+// It can be replaced with a fake clock in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// DefaultClock is the Clock which uses the system time.
+var DefaultClock Clock = realClock{}
+
+// WaitOptions configure WaitForCall.
+type WaitOptions struct {
+	// Interval is the initial polling interval.
+	// The polling backs off while the call does not change
+	// and resets to Interval whenever the call stage or status changes.
+	Interval time.Duration
+	// MaxInterval caps the polling interval.
+	MaxInterval time.Duration
+	// OnStage is called with the call whenever its stage
+	// or status changes, including the final call state.
+	OnStage func(call *Call)
+	// Clock is used for timing the polling
+	// and for reporting the time spent waiting.
+	Clock Clock
+}
+
+// IsTerminal returns true if the call status is final.
+func (s CallStatus) IsTerminal() bool {
+	return s == CallEnded || s == CallError
+}
+
+// WaitForCall polls the call with the given id until its status becomes
+// terminal and returns the final call. Use the ctx deadline to bound the wait;
+// the returned ctx error reports how long the call has been waited for.
+// Transient API errors are retried; other errors stop the polling.
+func (c *Client) WaitForCall(ctx context.Context, id string, opts *WaitOptions) (*Call, error) {
+	options := WaitOptions{
+		Interval:    DefaultWaitInterval,
+		MaxInterval: DefaultWaitMaxInterval,
+		Clock:       DefaultClock,
+	}
+	if opts != nil {
+		if opts.Interval > 0 {
+			options.Interval = opts.Interval
+		}
+		if opts.MaxInterval > 0 {
+			options.MaxInterval = opts.MaxInterval
+		}
+		if opts.Clock != nil {
+			options.Clock = opts.Clock
+		}
+		options.OnStage = opts.OnStage
+	}
+	options.MaxInterval = max(options.MaxInterval, options.Interval)
+
+	var (
+		prev     *Call
+		interval = options.Interval
+		start    = options.Clock.Now()
+	)
+	stopped := func() error {
+		elapsed := options.Clock.Now().Sub(start).Round(time.Millisecond)
+		return fmt.Errorf("call %s not finished after %s: %w", id, elapsed, ctx.Err())
+	}
+	for {
+		call, err := c.GetCall(ctx, id)
+		switch {
+		case err == nil:
+			changed := prev == nil || call.Stage != prev.Stage || call.Status != prev.Status
+			if changed && options.OnStage != nil {
+				options.OnStage(call)
+			}
+			if call.Status.IsTerminal() {
+				return call, nil
+			}
+			if changed {
+				interval = options.Interval
+			} else {
+				interval = min(interval*3/2, options.MaxInterval)
+			}
+			prev = call
+		case ctx.Err() != nil:
+			return nil, stopped()
+		case !IsRetryable(err):
+			return nil, err
+		default:
+			interval = min(interval*2, options.MaxInterval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, stopped()
+		case <-options.Clock.After(interval):
+		}
+	}
+}
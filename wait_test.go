@@ -0,0 +1,157 @@
+package vocode_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/vocodetest"
+)
+
+// fakeClock fires all the timers immediately, records
+// their durations and calls onWait before firing them.
+type fakeClock struct {
+	waits  []time.Duration
+	onWait func(n int)
+}
+
+func (c *fakeClock) Now() time.Time { return time.Now() }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	if c.onWait != nil {
+		c.onWait(len(c.waits))
+	}
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+	return ch
+}
+
+// createCall creates a call with all its dependencies.
+func createCall(t *testing.T, c *vocode.Client, toNumber string) *vocode.Call {
+	t.Helper()
+	ctx := context.Background()
+
+	prompt, err := c.CreatePrompt(ctx, &vocode.CreatePromptReq{PromptReq: vocode.PromptReq{Content: "test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	voice, err := c.CreateVoice(ctx, &vocode.CreateVoiceReq{VoiceReq: vocode.VoiceReq{Type: vocode.RimeVoiceType, RimeVoice: &vocode.RimeVoice{Speaker: "test"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent, err := c.CreateAgent(ctx, &vocode.CreateAgentReq{AgentReq: vocode.AgentReq{Name: "test", Prompt: prompt.ID, Voice: voice.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	number, err := c.BuyNumber(ctx, &vocode.BuyNumberReq{AreaCode: "415", TelProvider: vocode.TwilioTelProvider})
+	if err != nil {
+		t.Fatal(err)
+	}
+	call, err := c.CreateCall(ctx, &vocode.CreateCallReq{FromNr: number.Number, ToNr: toNumber, Agent: agent.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return call
+}
+
+func TestWaitForCall(t *testing.T) {
+	t.Parallel()
+	t.Run("stages", func(t *testing.T) {
+		t.Parallel()
+		s := vocodetest.NewServer(vocodetest.WithAutoAdvance())
+		t.Cleanup(s.Close)
+		c := s.Client()
+
+		s.SetCallScript("+15550100", vocodetest.CallScript{
+			Stages:  []vocode.CallStage{vocode.CallPickedUp, vocode.CallTransferStart, vocode.CallTransferSuccess},
+			Outcome: vocode.CallStageTransferDisconnect,
+		})
+		call := createCall(t, c, "+15550100")
+
+		var stages []vocode.CallStage
+		clock := &fakeClock{}
+		call, err := c.WaitForCall(context.Background(), call.ID, &vocode.WaitOptions{
+			Clock:   clock,
+			OnStage: func(call *vocode.Call) { stages = append(stages, call.Stage) },
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if call.Status != vocode.CallEnded || call.StageOutcome != vocode.CallStageTransferDisconnect {
+			t.Fatalf("unexpected call: %+v", call)
+		}
+		expStages := []vocode.CallStage{vocode.CallPickedUp, vocode.CallTransferStart, vocode.CallTransferSuccess, vocode.CallTransferSuccess}
+		if !reflect.DeepEqual(stages, expStages) {
+			t.Fatalf("expected stages: %v, got: %v", expStages, stages)
+		}
+		for _, d := range clock.waits {
+			if d != vocode.DefaultWaitInterval {
+				t.Fatalf("expected wait: %v, got: %v", vocode.DefaultWaitInterval, d)
+			}
+		}
+	})
+	t.Run("backoff", func(t *testing.T) {
+		t.Parallel()
+		s := vocodetest.NewServer()
+		t.Cleanup(s.Close)
+		c := s.Client()
+
+		call := createCall(t, c, "+15550111")
+		clock := &fakeClock{
+			onWait: func(n int) {
+				if n == 4 {
+					if _, err := s.CompleteCall(call.ID); err != nil {
+						t.Error(err)
+					}
+				}
+			},
+		}
+		call, err := c.WaitForCall(context.Background(), call.ID, &vocode.WaitOptions{
+			Interval:    time.Second,
+			MaxInterval: 2 * time.Second,
+			Clock:       clock,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if call.Status != vocode.CallEnded {
+			t.Fatalf("expected call status: %s, got: %s", vocode.CallEnded, call.Status)
+		}
+		expWaits := []time.Duration{time.Second, 1500 * time.Millisecond, 2 * time.Second, 2 * time.Second}
+		if !reflect.DeepEqual(clock.waits, expWaits) {
+			t.Fatalf("expected waits: %v, got: %v", expWaits, clock.waits)
+		}
+	})
+	t.Run("not_found", func(t *testing.T) {
+		t.Parallel()
+		s := vocodetest.NewServer()
+		t.Cleanup(s.Close)
+
+		_, err := s.Client().WaitForCall(context.Background(), "missing", &vocode.WaitOptions{Clock: &fakeClock{}})
+		if !errors.Is(err, vocode.ErrNotFound) {
+			t.Fatalf("expected error: %v, got: %v", vocode.ErrNotFound, err)
+		}
+	})
+	t.Run("deadline", func(t *testing.T) {
+		t.Parallel()
+		s := vocodetest.NewServer()
+		t.Cleanup(s.Close)
+		c := s.Client()
+
+		call := createCall(t, c, "+15550122")
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := c.WaitForCall(ctx, call.ID, &vocode.WaitOptions{Interval: 10 * time.Millisecond})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected error: %v, got: %v", context.DeadlineExceeded, err)
+		}
+		if !strings.Contains(err.Error(), "call "+call.ID+" not finished after ") {
+			t.Fatalf("expected elapsed wait in error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// StatusError is an error which carries the HTTP status
+// code the webhook delivery is responded with.
+type StatusError struct {
+	Code int
+	Err  error
+}
+
+// Error implements error interface.
+func (e *StatusError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// WithStatus wraps err so the webhook delivery
+// that caused it is responded with the status code.
+func WithStatus(code int, err error) error {
+	return &StatusError{Code: code, Err: err}
+}
+
+// StatusCode returns the HTTP status code for the handler error.
+// Nil error maps to 200 OK, StatusError to its status code and
+// context cancellations to 503 Service Unavailable.
+// All the other errors map to 500 Internal Server Error.
+func StatusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,210 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+var (
+	// ErrMissingType is returned when the event payload does not specify the event type.
+	ErrMissingType = errors.New("missing event type")
+)
+
+// Event is implemented by all decoded webhook events.
+type Event interface {
+	// EventType returns the type of the event.
+	EventType() vocode.Event
+	// EventCall returns the call the event was delivered for.
+	EventCall() *vocode.Call
+}
+
+// MessageEvent is delivered when a message is sent during the call.
+type MessageEvent struct {
+	vocode.Call
+	Sender string `json:"sender"`
+	Text   string `json:"text"`
+}
+
+// EventType implements Event.
+func (e *MessageEvent) EventType() vocode.Event { return vocode.MessageEvent }
+
+// EventCall implements Event.
+func (e *MessageEvent) EventCall() *vocode.Call { return &e.Call }
+
+// ActionEvent is delivered when an agent action is executed.
+type ActionEvent struct {
+	vocode.Call
+	ActionType vocode.ActionType `json:"action_type"`
+	Payload    map[string]any    `json:"payload"`
+}
+
+// EventType implements Event.
+func (e *ActionEvent) EventType() vocode.Event { return vocode.ActionEvent }
+
+// EventCall implements Event.
+func (e *ActionEvent) EventCall() *vocode.Call { return &e.Call }
+
+// CallConnectedEvent is delivered when the phone call connects.
+type CallConnectedEvent struct {
+	vocode.Call
+}
+
+// EventType implements Event.
+func (e *CallConnectedEvent) EventType() vocode.Event { return vocode.CallConnectedEvent }
+
+// EventCall implements Event.
+func (e *CallConnectedEvent) EventCall() *vocode.Call { return &e.Call }
+
+// CallEndedEvent is delivered when the phone call ends.
+type CallEndedEvent struct {
+	vocode.Call
+}
+
+// EventType implements Event.
+func (e *CallEndedEvent) EventType() vocode.Event { return vocode.CallEndedEvent }
+
+// EventCall implements Event.
+func (e *CallEndedEvent) EventCall() *vocode.Call { return &e.Call }
+
+// CallDidntConnectEvent is delivered when the phone call did not connect.
+type CallDidntConnectEvent struct {
+	vocode.Call
+}
+
+// EventType implements Event.
+func (e *CallDidntConnectEvent) EventType() vocode.Event { return vocode.CallDidntConnectEvent }
+
+// EventCall implements Event.
+func (e *CallDidntConnectEvent) EventCall() *vocode.Call { return &e.Call }
+
+// TranscriptEvent is delivered when the call transcript is available.
+type TranscriptEvent struct {
+	vocode.Call
+}
+
+// EventType implements Event.
+func (e *TranscriptEvent) EventType() vocode.Event { return vocode.TranscriptEvent }
+
+// EventCall implements Event.
+func (e *TranscriptEvent) EventCall() *vocode.Call { return &e.Call }
+
+// RecordingEvent is delivered when the call recording is available.
+type RecordingEvent struct {
+	vocode.Call
+}
+
+// EventType implements Event.
+func (e *RecordingEvent) EventType() vocode.Event { return vocode.RecordingEvent }
+
+// EventCall implements Event.
+func (e *RecordingEvent) EventCall() *vocode.Call { return &e.Call }
+
+// HumanDetectionEvent is delivered when the human detection finishes.
+type HumanDetectionEvent struct {
+	vocode.Call
+}
+
+// EventType implements Event.
+func (e *HumanDetectionEvent) EventType() vocode.Event { return vocode.HumanDetectionEvent }
+
+// EventCall implements Event.
+func (e *HumanDetectionEvent) EventCall() *vocode.Call { return &e.Call }
+
+// UnknownEvent is returned for event types this package does not know about.
+type UnknownEvent struct {
+	vocode.Call
+	Type vocode.Event    `json:"-"`
+	Raw  json.RawMessage `json:"-"`
+}
+
+// EventType implements Event.
+func (e *UnknownEvent) EventType() vocode.Event { return e.Type }
+
+// EventCall implements Event.
+func (e *UnknownEvent) EventCall() *vocode.Call { return &e.Call }
+
+// payload is the wire format of the webhook event.
+// The call the event was delivered for is stored
+// in the call field; any event specific fields
+// are stored alongside the event type.
+type payload struct {
+	Type       vocode.Event      `json:"type"`
+	Call       json.RawMessage   `json:"call,omitempty"`
+	Sender     string            `json:"sender,omitempty"`
+	Text       string            `json:"text,omitempty"`
+	ActionType vocode.ActionType `json:"action_type,omitempty"`
+	Payload    map[string]any    `json:"payload,omitempty"`
+}
+
+// Decode decodes the JSON encoded webhook event payload.
+// Events whose type is not known are returned as UnknownEvent.
+func Decode(data []byte) (Event, error) {
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	if p.Type == "" {
+		return nil, ErrMissingType
+	}
+
+	var call vocode.Call
+	if len(p.Call) > 0 {
+		if err := json.Unmarshal(p.Call, &call); err != nil {
+			return nil, fmt.Errorf("decode %s call: %w", p.Type, err)
+		}
+	}
+
+	switch p.Type {
+	case vocode.MessageEvent:
+		return &MessageEvent{Call: call, Sender: p.Sender, Text: p.Text}, nil
+	case vocode.ActionEvent:
+		return &ActionEvent{Call: call, ActionType: p.ActionType, Payload: p.Payload}, nil
+	case vocode.CallConnectedEvent:
+		return &CallConnectedEvent{Call: call}, nil
+	case vocode.CallEndedEvent:
+		return &CallEndedEvent{Call: call}, nil
+	case vocode.CallDidntConnectEvent:
+		return &CallDidntConnectEvent{Call: call}, nil
+	case vocode.TranscriptEvent:
+		return &TranscriptEvent{Call: call}, nil
+	case vocode.RecordingEvent:
+		return &RecordingEvent{Call: call}, nil
+	case vocode.HumanDetectionEvent:
+		return &HumanDetectionEvent{Call: call}, nil
+	}
+
+	raw := make(json.RawMessage, len(data))
+	copy(raw, data)
+
+	return &UnknownEvent{Call: call, Type: p.Type, Raw: raw}, nil
+}
+
+// Encode encodes the event into its JSON wire format.
+// It is the inverse of Decode and is mostly useful in tests.
+func Encode(e Event) ([]byte, error) {
+	if u, ok := e.(*UnknownEvent); ok && len(u.Raw) > 0 {
+		return u.Raw, nil
+	}
+
+	call, err := json.Marshal(e.EventCall())
+	if err != nil {
+		return nil, err
+	}
+
+	p := payload{
+		Type: e.EventType(),
+		Call: call,
+	}
+
+	switch v := e.(type) {
+	case *MessageEvent:
+		p.Sender, p.Text = v.Sender, v.Text
+	case *ActionEvent:
+		p.ActionType, p.Payload = v.ActionType, v.Payload
+	}
+
+	return json.Marshal(p)
+}
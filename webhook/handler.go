@@ -0,0 +1,181 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DefaultMaxBodySize is the default maximum size of the webhook request body.
+	DefaultMaxBodySize int64 = 1 << 20
+)
+
+var (
+	// ErrMethodNotAllowed is returned when the webhook is delivered via unsupported HTTP method.
+	ErrMethodNotAllowed = errors.New("method not allowed")
+	// ErrBodyTooLarge is returned when the webhook request body exceeds the maximum size.
+	ErrBodyTooLarge = errors.New("request body too large")
+)
+
+// HandlerFunc handles the decoded webhook event.
+type HandlerFunc func(ctx context.Context, e Event) error
+
+// Handler is an http.Handler which decodes
+// the webhook events delivered by Vocode and
+// passes them to the configured HandlerFunc.
+type Handler struct {
+	fn          HandlerFunc
+	maxBodySize int64
+	verifier    *Verifier
+}
+
+// Options configure the webhook Handler.
+type Options struct {
+	MaxBodySize int64
+	Verifier    *Verifier
+}
+
+// Option is functional webhook handler option.
+type Option func(*Options)
+
+// NewHandler creates a new webhook Handler which calls fn
+// for every successfully decoded webhook event.
+func NewHandler(fn HandlerFunc, opts ...Option) *Handler {
+	options := Options{
+		MaxBodySize: DefaultMaxBodySize,
+	}
+	for _, apply := range opts {
+		apply(&options)
+	}
+
+	return &Handler{
+		fn:          fn,
+		maxBodySize: options.MaxBodySize,
+		verifier:    options.Verifier,
+	}
+}
+
+// WithMaxBodySize sets the maximum webhook request body size.
+func WithMaxBodySize(size int64) Option {
+	return func(o *Options) {
+		o.MaxBodySize = size
+	}
+}
+
+// WithVerifier sets the verifier used to authenticate the webhook requests.
+func WithVerifier(v *Verifier) Option {
+	return func(o *Options) {
+		o.Verifier = v
+	}
+}
+
+// ServeHTTP implements http.Handler.
+// Requests which fail the verification, if the Verifier
+// has been configured, are rejected with 401 Unauthorized.
+// Malformed deliveries are rejected with 400 Bad Request.
+// Errors returned by the HandlerFunc are mapped to HTTP
+// status codes by StatusCode so that Vocode can retry them.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.maxBodySize > 0 && r.Body != nil {
+		// NOTE: the body is limited before it's read by the verifier.
+		r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+	}
+
+	if h.verifier != nil {
+		if err := h.verifier.Verify(r); err != nil {
+			http.Error(w, err.Error(), verifyStatus(err))
+			return
+		}
+	}
+
+	data, err := ReadRequest(r, h.maxBodySize)
+	if err != nil {
+		if errors.Is(err, ErrMethodNotAllowed) {
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, err.Error(), http.StatusMethodNotAllowed)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	e, err := Decode(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.fn(r.Context(), e); err != nil {
+		code := StatusCode(err)
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ReadRequest reads the raw JSON event payload from the webhook request.
+// PostWebhook deliveries carry the payload in the request body
+// which is read up to maxBodySize bytes; if maxBodySize is not positive
+// the body size is not limited. GetWebhook deliveries carry the payload
+// fields in URL query parameters; the object valued ones JSON encoded.
+func ReadRequest(r *http.Request, maxBodySize int64) ([]byte, error) {
+	switch r.Method {
+	case http.MethodPost:
+		return readBody(r, maxBodySize)
+	case http.MethodGet:
+		return queryPayload(r.URL.Query())
+	}
+	return nil, ErrMethodNotAllowed
+}
+
+// readBody reads the request body up to maxBodySize bytes.
+// If maxBodySize is not positive the body size is not limited.
+func readBody(r *http.Request, maxBodySize int64) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	body := io.Reader(r.Body)
+	if maxBodySize > 0 {
+		body = io.LimitReader(r.Body, maxBodySize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, fmt.Errorf("%w: exceeds %d bytes", ErrBodyTooLarge, maxErr.Limit)
+		}
+		return nil, err
+	}
+	if maxBodySize > 0 && int64(len(data)) > maxBodySize {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrBodyTooLarge, maxBodySize)
+	}
+	return data, nil
+}
+
+// queryPayload converts the URL query values to a JSON object.
+func queryPayload(q url.Values) ([]byte, error) {
+	obj := make(map[string]json.RawMessage, len(q))
+	for k := range q {
+		v := strings.TrimSpace(q.Get(k))
+		if strings.HasPrefix(v, "{") || strings.HasPrefix(v, "[") {
+			if !json.Valid([]byte(v)) {
+				return nil, fmt.Errorf("invalid JSON in query parameter %q", k)
+			}
+			obj[k] = json.RawMessage(v)
+			continue
+		}
+		s, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		obj[k] = s
+	}
+	return json.Marshal(obj)
+}
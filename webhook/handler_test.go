@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+const callEndedPayload = `{"type":"event_phone_call_ended","call":{"id":"call-1","status":"ended","stage_outcome":"human_disconnected","agent":"agent-1"}}`
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+	t.Run("call_ended", func(t *testing.T) {
+		t.Parallel()
+		e, err := Decode([]byte(callEndedPayload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ev, ok := e.(*CallEndedEvent)
+		if !ok {
+			t.Fatalf("expected %T, got: %T", &CallEndedEvent{}, e)
+		}
+		if ev.ID != "call-1" || ev.Status != vocode.CallEnded || ev.Agent.ID != "agent-1" {
+			t.Fatalf("unexpected call: %+v", ev.Call)
+		}
+	})
+	t.Run("message", func(t *testing.T) {
+		t.Parallel()
+		e, err := Decode([]byte(`{"type":"event_message","call":{"id":"call-1"},"sender":"bot","text":"hello"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ev, ok := e.(*MessageEvent)
+		if !ok {
+			t.Fatalf("expected %T, got: %T", &MessageEvent{}, e)
+		}
+		if ev.Text != "hello" || ev.Sender != "bot" {
+			t.Fatalf("unexpected message event: %+v", ev)
+		}
+	})
+	t.Run("unknown", func(t *testing.T) {
+		t.Parallel()
+		e, err := Decode([]byte(`{"type":"event_foo","call":{"id":"call-1"}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := e.(*UnknownEvent); !ok {
+			t.Fatalf("expected %T, got: %T", &UnknownEvent{}, e)
+		}
+		if e.EventType() != "event_foo" {
+			t.Fatalf("expected event type: %s, got: %s", "event_foo", e.EventType())
+		}
+	})
+	t.Run("missing_type", func(t *testing.T) {
+		t.Parallel()
+		if _, err := Decode([]byte(`{"call":{"id":"call-1"}}`)); !errors.Is(err, ErrMissingType) {
+			t.Fatalf("expected error: %v, got: %v", ErrMissingType, err)
+		}
+	})
+	t.Run("roundtrip", func(t *testing.T) {
+		t.Parallel()
+		in := &MessageEvent{Call: vocode.Call{ID: "call-1"}, Sender: "human", Text: "hi"}
+		data, err := Encode(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := Decode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, ok := e.(*MessageEvent)
+		if !ok || out.ID != in.ID || out.Text != in.Text || out.Sender != in.Sender {
+			t.Fatalf("expected event: %+v, got: %+v", in, e)
+		}
+	})
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	t.Run("post", func(t *testing.T) {
+		t.Parallel()
+		var got Event
+		h := NewHandler(func(_ context.Context, e Event) error {
+			got = e
+			return nil
+		})
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(callEndedPayload))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status: %d, got: %d", http.StatusOK, rec.Code)
+		}
+		if got == nil || got.EventCall().ID != "call-1" {
+			t.Fatalf("unexpected event: %+v", got)
+		}
+	})
+	t.Run("get", func(t *testing.T) {
+		t.Parallel()
+		var got Event
+		h := NewHandler(func(_ context.Context, e Event) error {
+			got = e
+			return nil
+		})
+		q := url.Values{}
+		q.Set("type", string(vocode.TranscriptEvent))
+		q.Set("call", `{"id":"call-2","transcript":"BOT: hi"}`)
+		req := httptest.NewRequest(http.MethodGet, "/webhook?"+q.Encode(), nil)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status: %d, got: %d", http.StatusOK, rec.Code)
+		}
+		ev, ok := got.(*TranscriptEvent)
+		if !ok || ev.Transcript != "BOT: hi" {
+			t.Fatalf("unexpected event: %+v", got)
+		}
+	})
+	t.Run("bad_request", func(t *testing.T) {
+		t.Parallel()
+		h := NewHandler(func(context.Context, Event) error { return nil })
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{`))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status: %d, got: %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+	t.Run("body_too_large", func(t *testing.T) {
+		t.Parallel()
+		h := NewHandler(func(context.Context, Event) error { return nil }, WithMaxBodySize(8))
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(callEndedPayload))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status: %d, got: %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+	t.Run("method_not_allowed", func(t *testing.T) {
+		t.Parallel()
+		h := NewHandler(func(context.Context, Event) error { return nil })
+		req := httptest.NewRequest(http.MethodPut, "/webhook", nil)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("expected status: %d, got: %d", http.StatusMethodNotAllowed, rec.Code)
+		}
+	})
+	t.Run("handler_error", func(t *testing.T) {
+		t.Parallel()
+		h := NewHandler(func(context.Context, Event) error { return errors.New("boom") })
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(callEndedPayload))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status: %d, got: %d", http.StatusInternalServerError, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,291 @@
+package webhook
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts is the default maximum number of handler attempts.
+	DefaultMaxAttempts = 5
+	// DefaultMinBackoff is the default delay before the first redelivery.
+	DefaultMinBackoff = time.Second
+	// DefaultMaxBackoff is the default maximum delay between redeliveries.
+	DefaultMaxBackoff = 5 * time.Minute
+	// DefaultPollInterval is the default interval the inbox checks for due records.
+	DefaultPollInterval = time.Second
+)
+
+// Inbox is a durable webhook inbox.
+// It persists every delivered event in the Store before
+// it acknowledges its delivery and it then hands the stored
+// events to the HandlerFunc, retrying the failed ones with
+// exponential backoff until they either succeed or
+// exhaust their attempts and end up in the dead-letter list.
+type Inbox struct {
+	store Store
+	fn    HandlerFunc
+	opts  InboxOptions
+}
+
+// InboxOptions configure the Inbox.
+type InboxOptions struct {
+	MaxBodySize  int64
+	MaxAttempts  int
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+	PollInterval time.Duration
+	Now          func() time.Time
+}
+
+// InboxOption is functional inbox option.
+type InboxOption func(*InboxOptions)
+
+// NewInbox creates a new Inbox which stores the events
+// in store and passes them to fn and returns it.
+func NewInbox(store Store, fn HandlerFunc, opts ...InboxOption) *Inbox {
+	options := InboxOptions{
+		MaxBodySize:  DefaultMaxBodySize,
+		MaxAttempts:  DefaultMaxAttempts,
+		MinBackoff:   DefaultMinBackoff,
+		MaxBackoff:   DefaultMaxBackoff,
+		PollInterval: DefaultPollInterval,
+		Now:          time.Now,
+	}
+	for _, apply := range opts {
+		apply(&options)
+	}
+
+	return &Inbox{
+		store: store,
+		fn:    fn,
+		opts:  options,
+	}
+}
+
+// WithInboxMaxBodySize sets the maximum webhook request body size.
+func WithInboxMaxBodySize(size int64) InboxOption {
+	return func(o *InboxOptions) {
+		o.MaxBodySize = size
+	}
+}
+
+// WithMaxAttempts sets the maximum number of handler attempts.
+func WithMaxAttempts(n int) InboxOption {
+	return func(o *InboxOptions) {
+		o.MaxAttempts = n
+	}
+}
+
+// WithBackoff sets the minimum and maximum redelivery backoff.
+func WithBackoff(min, max time.Duration) InboxOption {
+	return func(o *InboxOptions) {
+		o.MinBackoff = min
+		o.MaxBackoff = max
+	}
+}
+
+// WithPollInterval sets the interval the inbox checks for due records.
+func WithPollInterval(d time.Duration) InboxOption {
+	return func(o *InboxOptions) {
+		o.PollInterval = d
+	}
+}
+
+// WithInboxNow sets the function which returns the current time.
+func WithInboxNow(now func() time.Time) InboxOption {
+	return func(o *InboxOptions) {
+		o.Now = now
+	}
+}
+
+// ServeHTTP implements http.Handler.
+// It acknowledges the delivery with 200 OK once the event has been
+// stored; the redeliveries of the already stored events, which have the
+// same Key, are acknowledged without storing them again. Malformed deliveries are rejected with
+// 400 Bad Request and storage failures with 503 Service Unavailable.
+func (i *Inbox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := ReadRequest(r, i.opts.MaxBodySize)
+	if err != nil {
+		if errors.Is(err, ErrMethodNotAllowed) {
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, err.Error(), http.StatusMethodNotAllowed)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	e, err := Decode(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, _, err := i.Accept(r.Context(), e); err != nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Accept stores the decoded event in the inbox.
+// It returns the stored record and true if the event was stored or
+// false if it is a redelivery of the event that had already been
+// stored before.
+func (i *Inbox) Accept(ctx context.Context, e Event) (*Record, bool, error) {
+	data, err := Encode(e)
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := i.opts.Now()
+	r := &Record{
+		Key:         Key(e),
+		Type:        e.EventType(),
+		CallID:      e.EventCall().ID,
+		Payload:     data,
+		Status:      RecordPending,
+		ReceivedAt:  now,
+		NextAttempt: now,
+	}
+
+	added, err := i.store.Add(ctx, r)
+	if err != nil {
+		return nil, false, err
+	}
+	if !added {
+		r, err = i.store.Get(ctx, r.Key)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return r, added, nil
+}
+
+// Key returns the deduplication key of the event.
+// The key is made of the call ID and the event type, which identify the
+// events delivered once per call, e.g. the call ended events. Vocode
+// delivers no event IDs, so the message and action events, of which there
+// are many per call, are identified by the digest of their whole payload:
+// it includes the state of the call at the time of the event, e.g. its
+// transcript which grows with every message, so the repeated identical
+// messages do not collide while their redeliveries deduplicate.
+func Key(e Event) string {
+	parts := []string{e.EventCall().ID, string(e.EventType())}
+
+	switch e.(type) {
+	case *MessageEvent, *ActionEvent, *UnknownEvent:
+		// NOTE: the payload is decoded and encoded again so the
+		// digest does not depend on the formatting of the delivery;
+		// it was decoded from JSON so it can't fail to be encoded.
+		data, _ := Encode(e)
+		var v any
+		_ = json.Unmarshal(data, &v)
+		data, _ = json.Marshal(v)
+		sum := sha256.Sum256(data)
+		parts = append(parts, hex.EncodeToString(sum[:]))
+	}
+	return strings.Join(parts, "|")
+}
+
+// Run processes the due inbox records every
+// poll interval until the context is cancelled.
+func (i *Inbox) Run(ctx context.Context) error {
+	ticker := time.NewTicker(i.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := i.Process(ctx); err != nil && ctx.Err() == nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process hands all the due pending records to the handler
+// and returns the number of records it has handled successfully.
+// Failed records are rescheduled with exponential backoff
+// or moved to the dead-letter list once they exhaust their attempts.
+func (i *Inbox) Process(ctx context.Context) (int, error) {
+	records, err := i.store.List(ctx, RecordPending)
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+	for _, r := range records {
+		if err := ctx.Err(); err != nil {
+			return done, err
+		}
+		if r.NextAttempt.After(i.opts.Now()) {
+			continue
+		}
+
+		e, err := Decode(r.Payload)
+		if err == nil {
+			err = i.fn(ctx, e)
+		}
+		r.Attempts++
+
+		switch {
+		case err == nil:
+			r.Status = RecordDone
+			r.LastError = ""
+			done++
+		case r.Attempts >= i.opts.MaxAttempts:
+			r.Status = RecordDead
+			r.LastError = err.Error()
+		default:
+			r.NextAttempt = i.opts.Now().Add(i.backoff(r.Attempts))
+			r.LastError = err.Error()
+		}
+
+		if err := i.store.Update(ctx, r); err != nil {
+			return done, err
+		}
+	}
+
+	return done, nil
+}
+
+// backoff returns the delay before the next attempt.
+func (i *Inbox) backoff(attempts int) time.Duration {
+	d := i.opts.MinBackoff
+	for n := 1; n < attempts; n++ {
+		d *= 2
+		if d >= i.opts.MaxBackoff {
+			return i.opts.MaxBackoff
+		}
+	}
+	return d
+}
+
+// DeadLetters returns the records which exhausted their attempts.
+func (i *Inbox) DeadLetters(ctx context.Context) ([]*Record, error) {
+	return i.store.List(ctx, RecordDead)
+}
+
+// Requeue moves the dead-letter record back to the pending
+// records and resets its attempts so it is handled again.
+func (i *Inbox) Requeue(ctx context.Context, key string) error {
+	r, err := i.store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	r.Status = RecordPending
+	r.Attempts = 0
+	r.NextAttempt = i.opts.Now()
+	return i.store.Update(ctx, r)
+}
@@ -0,0 +1,209 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInbox(t *testing.T) {
+	t.Parallel()
+	t.Run("dedupe", func(t *testing.T) {
+		t.Parallel()
+		store, err := NewFileStore(filepath.Join(t.TempDir(), "inbox.jsonl"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+
+		inbox := NewInbox(store, func(context.Context, Event) error { return nil })
+		deliver := func(payload string) {
+			t.Helper()
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+			rec := httptest.NewRecorder()
+			inbox.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status: %d, got: %d", http.StatusOK, rec.Code)
+			}
+		}
+
+		// the redeliveries of the call events differ in their formatting and call fields
+		deliver(callEndedPayload)
+		deliver(callEndedPayload)
+		deliver(`{"call": {"id": "call-1", "status": "ended", "transcript": "BOT: bye"}, "type": "event_phone_call_ended"}`)
+		// the redeliveries of the messages differ in their formatting
+		deliver(`{"type":"event_message","call":{"id":"call-1","transcript":"BOT: ok?\nHUMAN: yes"},"sender":"human","text":"yes"}`)
+		deliver(`{"type": "event_message", "text": "yes", "sender": "human", "call": {"transcript": "BOT: ok?\nHUMAN: yes", "id": "call-1"}}`)
+		// the repeated identical messages are not duplicates
+		deliver(`{"type":"event_message","call":{"id":"call-1","transcript":"BOT: ok?\nHUMAN: yes\nBOT: sure?\nHUMAN: yes"},"sender":"human","text":"yes"}`)
+
+		pending, err := store.List(context.Background(), RecordPending)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) != 3 {
+			t.Fatalf("expected pending records: %d, got: %d", 3, len(pending))
+		}
+	})
+	t.Run("retry_and_dead_letter", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "inbox.jsonl")
+		store, err := NewFileStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		now := time.Unix(1700000000, 0)
+		clock := func() time.Time { return now }
+		calls := 0
+		fn := func(context.Context, Event) error {
+			calls++
+			return errors.New("downstream unavailable")
+		}
+		inbox := NewInbox(store, fn, WithMaxAttempts(3), WithBackoff(time.Second, 10*time.Second), WithInboxNow(clock))
+
+		ctx := context.Background()
+		e, err := Decode([]byte(callEndedPayload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, added, err := inbox.Accept(ctx, e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !added {
+			t.Fatal("expected record to be added")
+		}
+
+		// first attempt fails and is rescheduled after 1s
+		if _, err := inbox.Process(ctx); err != nil {
+			t.Fatal(err)
+		}
+		// not due yet
+		if _, err := inbox.Process(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if calls != 1 {
+			t.Fatalf("expected handler calls: %d, got: %d", 1, calls)
+		}
+		now = now.Add(time.Second)
+		if _, err := inbox.Process(ctx); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(2 * time.Second)
+		if _, err := inbox.Process(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if calls != 3 {
+			t.Fatalf("expected handler calls: %d, got: %d", 3, calls)
+		}
+
+		dead, err := inbox.DeadLetters(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dead) != 1 || dead[0].Key != r.Key {
+			t.Fatalf("expected dead letter: %s, got: %+v", r.Key, dead)
+		}
+		if err := store.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		// reopen the store and replay the requeued record
+		store, err = NewFileStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+
+		var got Event
+		inbox = NewInbox(store, func(_ context.Context, e Event) error {
+			got = e
+			return nil
+		}, WithInboxNow(clock))
+		if err := inbox.Requeue(ctx, r.Key); err != nil {
+			t.Fatal(err)
+		}
+		n, err := inbox.Process(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 || got == nil || got.EventCall().ID != "call-1" {
+			t.Fatalf("expected replayed event, got: %d %+v", n, got)
+		}
+		if err := store.Compact(); err != nil {
+			t.Fatal(err)
+		}
+		rec, err := store.Get(ctx, r.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Status != RecordDone {
+			t.Fatalf("expected status: %s, got: %s", RecordDone, rec.Status)
+		}
+	})
+	t.Run("partial_write", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "inbox.jsonl")
+		// the process crashed while writing the second record
+		if err := os.WriteFile(path, []byte(`{"key":"a","status":"pending"}`+"\n"+`{"key":"b","sta`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx := context.Background()
+		store, err := NewFileStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.Add(ctx, &Record{Key: "c", Status: RecordPending}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		// the new record is not appended to the partial one
+		store, err = NewFileStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+		pending, err := store.List(ctx, RecordPending)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) != 2 {
+			t.Fatalf("expected pending records: %d, got: %d", 2, len(pending))
+		}
+
+		// the corrupt records fail the store
+		if err := os.WriteFile(path, []byte("{\n"+`{"key":"a","status":"pending"}`+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewFileStore(path); err == nil {
+			t.Fatal("expected corrupt record error")
+		}
+	})
+	t.Run("bad_request", func(t *testing.T) {
+		t.Parallel()
+		store, err := NewFileStore(filepath.Join(t.TempDir(), "inbox.jsonl"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+
+		inbox := NewInbox(store, func(context.Context, Event) error { return nil })
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"call":{}}`))
+		rec := httptest.NewRecorder()
+		inbox.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status: %d, got: %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
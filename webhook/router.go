@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+// Middleware wraps the HandlerFunc.
+type Middleware func(HandlerFunc) HandlerFunc
+
+// Router dispatches the webhook events to the
+// handlers registered for their event types.
+type Router struct {
+	handlers map[vocode.Event]HandlerFunc
+	fallback HandlerFunc
+	mws      []Middleware
+	opts     []Option
+}
+
+// NewRouter creates a new Router and returns it.
+// The options configure the http.Handler the Router serves requests with.
+func NewRouter(opts ...Option) *Router {
+	return &Router{
+		handlers: make(map[vocode.Event]HandlerFunc),
+		opts:     opts,
+	}
+}
+
+// on registers the typed handler fn for the event type typ.
+func on[T Event](r *Router, typ vocode.Event, fn func(context.Context, T) error) {
+	r.handlers[typ] = func(ctx context.Context, e Event) error {
+		ev, ok := e.(T)
+		if !ok {
+			return fmt.Errorf("unexpected %s event: %T", typ, e)
+		}
+		return fn(ctx, ev)
+	}
+}
+
+// OnMessage registers the MessageEvent handler.
+func (r *Router) OnMessage(fn func(context.Context, *MessageEvent) error) {
+	on(r, vocode.MessageEvent, fn)
+}
+
+// OnAction registers the ActionEvent handler.
+func (r *Router) OnAction(fn func(context.Context, *ActionEvent) error) {
+	on(r, vocode.ActionEvent, fn)
+}
+
+// OnCallConnected registers the CallConnectedEvent handler.
+func (r *Router) OnCallConnected(fn func(context.Context, *CallConnectedEvent) error) {
+	on(r, vocode.CallConnectedEvent, fn)
+}
+
+// OnCallEnded registers the CallEndedEvent handler.
+func (r *Router) OnCallEnded(fn func(context.Context, *CallEndedEvent) error) {
+	on(r, vocode.CallEndedEvent, fn)
+}
+
+// OnCallDidntConnect registers the CallDidntConnectEvent handler.
+func (r *Router) OnCallDidntConnect(fn func(context.Context, *CallDidntConnectEvent) error) {
+	on(r, vocode.CallDidntConnectEvent, fn)
+}
+
+// OnTranscript registers the TranscriptEvent handler.
+func (r *Router) OnTranscript(fn func(context.Context, *TranscriptEvent) error) {
+	on(r, vocode.TranscriptEvent, fn)
+}
+
+// OnRecording registers the RecordingEvent handler.
+func (r *Router) OnRecording(fn func(context.Context, *RecordingEvent) error) {
+	on(r, vocode.RecordingEvent, fn)
+}
+
+// OnHumanDetection registers the HumanDetectionEvent handler.
+func (r *Router) OnHumanDetection(fn func(context.Context, *HumanDetectionEvent) error) {
+	on(r, vocode.HumanDetectionEvent, fn)
+}
+
+// OnUnknown registers the catch-all handler which is called
+// for all events that have no handler registered,
+// including the events of unknown types.
+func (r *Router) OnUnknown(fn HandlerFunc) {
+	r.fallback = fn
+}
+
+// Use appends the middlewares to the router middleware chain.
+// Middlewares wrap every event handler in the order they were added,
+// i.e. the first middleware is the outermost one.
+func (r *Router) Use(mws ...Middleware) {
+	r.mws = append(r.mws, mws...)
+}
+
+// Handle dispatches the event to its handler.
+// Events that have no handler registered and no catch-all
+// handler has been registered either are silently dropped.
+// Handle satisfies HandlerFunc so the router
+// can be passed to anything that accepts it.
+func (r *Router) Handle(ctx context.Context, e Event) error {
+	h := r.dispatch
+	for i := len(r.mws) - 1; i >= 0; i-- {
+		h = r.mws[i](h)
+	}
+	return h(ctx, e)
+}
+
+func (r *Router) dispatch(ctx context.Context, e Event) error {
+	if h, ok := r.handlers[e.EventType()]; ok {
+		return h(ctx, e)
+	}
+	if r.fallback != nil {
+		return r.fallback(ctx, e)
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	NewHandler(r.Handle, r.opts...).ServeHTTP(w, req)
+}
+
+// Logging returns a middleware which logs every handled event using logger.
+func Logging(logger *slog.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, e Event) error {
+			start := time.Now()
+			err := next(ctx, e)
+			attrs := []any{
+				slog.String("event", string(e.EventType())),
+				slog.String("call_id", e.EventCall().ID),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if err != nil {
+				logger.ErrorContext(ctx, "webhook event failed", append(attrs, slog.Any("error", err))...)
+				return err
+			}
+			logger.InfoContext(ctx, "webhook event handled", attrs...)
+			return nil
+		}
+	}
+}
+
+// Recover returns a middleware which recovers from panics
+// in the wrapped handlers and turns them into errors.
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, e Event) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("panic handling %s event: %v", e.EventType(), r)
+				}
+			}()
+			return next(ctx, e)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	t.Parallel()
+	t.Run("dispatch", func(t *testing.T) {
+		t.Parallel()
+		r := NewRouter()
+		var got *CallEndedEvent
+		r.OnCallEnded(func(_ context.Context, e *CallEndedEvent) error {
+			got = e
+			return nil
+		})
+		r.OnTranscript(func(context.Context, *TranscriptEvent) error {
+			t.Fatal("unexpected transcript event")
+			return nil
+		})
+		e, err := Decode([]byte(callEndedPayload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Handle(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+		if got == nil || got.ID != "call-1" {
+			t.Fatalf("unexpected event: %+v", got)
+		}
+	})
+	t.Run("catch_all", func(t *testing.T) {
+		t.Parallel()
+		r := NewRouter()
+		var got Event
+		r.OnUnknown(func(_ context.Context, e Event) error {
+			got = e
+			return nil
+		})
+		e, err := Decode([]byte(`{"type":"event_foo","call":{"id":"call-1"}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Handle(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+		if got == nil || got.EventType() != "event_foo" {
+			t.Fatalf("unexpected event: %+v", got)
+		}
+	})
+	t.Run("middleware_order", func(t *testing.T) {
+		t.Parallel()
+		r := NewRouter()
+		var order []string
+		mw := func(name string) Middleware {
+			return func(next HandlerFunc) HandlerFunc {
+				return func(ctx context.Context, e Event) error {
+					order = append(order, name)
+					return next(ctx, e)
+				}
+			}
+		}
+		r.Use(mw("first"), mw("second"))
+		r.OnCallEnded(func(context.Context, *CallEndedEvent) error {
+			order = append(order, "handler")
+			return nil
+		})
+		e, err := Decode([]byte(callEndedPayload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Handle(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(order, ",") != "first,second,handler" {
+			t.Fatalf("unexpected middleware order: %v", order)
+		}
+	})
+	t.Run("recover", func(t *testing.T) {
+		t.Parallel()
+		r := NewRouter()
+		r.Use(Recover())
+		r.OnCallEnded(func(context.Context, *CallEndedEvent) error {
+			panic("boom")
+		})
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(callEndedPayload))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status: %d, got: %d", http.StatusInternalServerError, rec.Code)
+		}
+	})
+	t.Run("status_error", func(t *testing.T) {
+		t.Parallel()
+		r := NewRouter()
+		r.OnCallEnded(func(context.Context, *CallEndedEvent) error {
+			return WithStatus(http.StatusTooManyRequests, errors.New("slow down"))
+		})
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(callEndedPayload))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected status: %d, got: %d", http.StatusTooManyRequests, rec.Code)
+		}
+	})
+}
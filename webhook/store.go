@@ -0,0 +1,188 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+	"github.com/milosgajdos/go-vocode/internal/jsonl"
+)
+
+var (
+	// ErrRecordNotFound is returned when the inbox record does not exist.
+	ErrRecordNotFound = errors.New("record not found")
+)
+
+// RecordStatus is the inbox record status.
+type RecordStatus string
+
+const (
+	// RecordPending records are waiting to be handled.
+	RecordPending RecordStatus = "pending"
+	// RecordDone records have been handled successfully.
+	RecordDone RecordStatus = "done"
+	// RecordDead records failed to be handled too many times.
+	RecordDead RecordStatus = "dead"
+)
+
+// Record is the webhook event stored in the inbox.
+type Record struct {
+	Key         string          `json:"key"`
+	Type        vocode.Event    `json:"type"`
+	CallID      string          `json:"call_id"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      RecordStatus    `json:"status"`
+	Attempts    int             `json:"attempts"`
+	ReceivedAt  time.Time       `json:"received_at"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	LastError   string          `json:"last_error,omitempty"`
+}
+
+// Store persists the inbox records.
+type Store interface {
+	// Add stores the new record. It returns false
+	// if the record with the same key already exists.
+	Add(ctx context.Context, r *Record) (bool, error)
+	// Update updates the existing record.
+	Update(ctx context.Context, r *Record) error
+	// Get returns the record with the given key.
+	Get(ctx context.Context, key string) (*Record, error)
+	// List returns all the records with the given status
+	// ordered by the time they were received.
+	List(ctx context.Context, status RecordStatus) ([]*Record, error)
+}
+
+// FileStore is a Store which keeps the records in memory
+// and persists every change to a JSONL file so that the
+// records survive process restarts. Every line in the file
+// holds the latest state of the record at the time of the write.
+type FileStore struct {
+	mu      sync.Mutex
+	log     *jsonl.Log
+	records map[string]*Record
+}
+
+// NewFileStore opens the JSONL file at path, creating it if
+// necessary, loads the records stored in it and returns the store.
+// It fails if the file contains a corrupt record.
+func NewFileStore(path string) (*FileStore, error) {
+	log, err := jsonl.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make(map[string]*Record)
+	err = log.Load(func(line []byte) error {
+		r := new(Record)
+		if err := json.Unmarshal(line, r); err != nil {
+			return err
+		}
+		records[r.Key] = r
+		return nil
+	})
+	if err != nil {
+		log.Close()
+		return nil, err
+	}
+
+	return &FileStore{
+		log:     log,
+		records: records,
+	}, nil
+}
+
+// Add implements Store.
+func (s *FileStore) Add(_ context.Context, r *Record) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.records[r.Key]; ok {
+		return false, nil
+	}
+	if err := s.write(r); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Update implements Store.
+func (s *FileStore) Update(_ context.Context, r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.records[r.Key]; !ok {
+		return ErrRecordNotFound
+	}
+	return s.write(r)
+}
+
+// Get implements Store.
+func (s *FileStore) Get(_ context.Context, key string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[key]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	rec := *r
+	return &rec, nil
+}
+
+// List implements Store.
+func (s *FileStore) List(_ context.Context, status RecordStatus) ([]*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []*Record
+	for _, r := range s.records {
+		if r.Status == status {
+			rec := *r
+			records = append(records, &rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].ReceivedAt.Before(records[j].ReceivedAt)
+	})
+	return records, nil
+}
+
+// Compact rewrites the store file so it only
+// contains the latest state of every record.
+func (s *FileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.log.Rewrite(func(add func(any) error) error {
+		for _, r := range s.records {
+			if err := add(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close closes the store file.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.log.Close()
+}
+
+// write appends the record to the file and
+// updates its in-memory state. It must be
+// called with the store mutex held.
+func (s *FileStore) write(r *Record) error {
+	if err := s.log.Append(r); err != nil {
+		return err
+	}
+	rec := *r
+	s.records[r.Key] = &rec
+	return nil
+}
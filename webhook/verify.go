@@ -0,0 +1,399 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/milosgajdos/go-vocode"
+)
+
+const (
+	// DefaultSignatureHeader is the default HMAC signature header.
+	DefaultSignatureHeader = "X-Vocode-Signature"
+	// DefaultTimestampHeader is the default signature timestamp header.
+	DefaultTimestampHeader = "X-Vocode-Timestamp"
+	// DefaultTokenHeader is the default shared secret header.
+	DefaultTokenHeader = "X-Vocode-Token"
+	// DefaultTokenParam is the default shared secret URL query parameter.
+	DefaultTokenParam = "token"
+	// DefaultTolerance is the default maximum age of the signature timestamp.
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	// ErrMissingSignature is returned when the request is not signed.
+	ErrMissingSignature = errors.New("missing webhook signature")
+	// ErrInvalidSignature is returned when the request signature does not match.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrMissingTimestamp is returned when the request signature timestamp is missing.
+	ErrMissingTimestamp = errors.New("missing webhook timestamp")
+	// ErrStaleTimestamp is returned when the request signature timestamp is outside of tolerance.
+	ErrStaleTimestamp = errors.New("stale webhook timestamp")
+	// ErrNoSecret is returned when the verifier has no secret configured.
+	ErrNoSecret = errors.New("no webhook secret")
+)
+
+// Scheme is webhook authentication scheme.
+type Scheme int
+
+const (
+	// HMACScheme authenticates the requests via HMAC-SHA256 signature
+	// of the signature timestamp and the payload, sent in headers.
+	HMACScheme Scheme = iota
+	// TokenScheme authenticates the requests via a shared secret token
+	// sent either in a header or in the webhook URL query parameter.
+	// The timestamp header is optional and the requests carrying the
+	// stale one are rejected, but since the timestamp is not signed the
+	// scheme gives no replay protection: it requires the HMACScheme,
+	// e.g. via a signing proxy in front of the webhook.
+	TokenScheme
+)
+
+// Verifier verifies the authenticity of the webhook requests.
+type Verifier struct {
+	secrets [][]byte
+	opts    VerifierOptions
+}
+
+// VerifierOptions configure the Verifier and Signer.
+type VerifierOptions struct {
+	Scheme          Scheme
+	SignatureHeader string
+	TimestampHeader string
+	TokenHeader     string
+	TokenParam      string
+	Tolerance       time.Duration
+	// MaxBodySize is the maximum size of the body read
+	// to verify the request. It is not limited if it's
+	// not positive.
+	MaxBodySize int64
+	Now         func() time.Time
+}
+
+// VerifierOption is functional verifier option.
+type VerifierOption func(*VerifierOptions)
+
+func newVerifierOptions(opts ...VerifierOption) VerifierOptions {
+	options := VerifierOptions{
+		Scheme:          HMACScheme,
+		SignatureHeader: DefaultSignatureHeader,
+		TimestampHeader: DefaultTimestampHeader,
+		TokenHeader:     DefaultTokenHeader,
+		TokenParam:      DefaultTokenParam,
+		Tolerance:       DefaultTolerance,
+		MaxBodySize:     DefaultMaxBodySize,
+		Now:             time.Now,
+	}
+	for _, apply := range opts {
+		apply(&options)
+	}
+	return options
+}
+
+// NewVerifier creates a new Verifier and returns it.
+// The first secret is the primary one; the others are accepted
+// as well, which allows rotating the secrets without downtime.
+func NewVerifier(secrets []string, opts ...VerifierOption) *Verifier {
+	keys := make([][]byte, 0, len(secrets))
+	for _, s := range secrets {
+		if s != "" {
+			keys = append(keys, []byte(s))
+		}
+	}
+
+	return &Verifier{
+		secrets: keys,
+		opts:    newVerifierOptions(opts...),
+	}
+}
+
+// WithScheme sets the authentication scheme.
+func WithScheme(s Scheme) VerifierOption {
+	return func(o *VerifierOptions) {
+		o.Scheme = s
+	}
+}
+
+// WithSignatureHeader sets the HMAC signature header.
+func WithSignatureHeader(h string) VerifierOption {
+	return func(o *VerifierOptions) {
+		o.SignatureHeader = h
+	}
+}
+
+// WithTimestampHeader sets the signature timestamp header.
+func WithTimestampHeader(h string) VerifierOption {
+	return func(o *VerifierOptions) {
+		o.TimestampHeader = h
+	}
+}
+
+// WithTokenHeader sets the shared secret token header.
+func WithTokenHeader(h string) VerifierOption {
+	return func(o *VerifierOptions) {
+		o.TokenHeader = h
+	}
+}
+
+// WithTokenParam sets the shared secret token URL query parameter.
+func WithTokenParam(p string) VerifierOption {
+	return func(o *VerifierOptions) {
+		o.TokenParam = p
+	}
+}
+
+// WithTolerance sets the maximum signature timestamp age.
+func WithTolerance(d time.Duration) VerifierOption {
+	return func(o *VerifierOptions) {
+		o.Tolerance = d
+	}
+}
+
+// WithVerifierMaxBodySize sets the maximum size of the verified request body.
+func WithVerifierMaxBodySize(size int64) VerifierOption {
+	return func(o *VerifierOptions) {
+		o.MaxBodySize = size
+	}
+}
+
+// WithNow sets the function which returns the current time.
+func WithNow(now func() time.Time) VerifierOption {
+	return func(o *VerifierOptions) {
+		o.Now = now
+	}
+}
+
+// Verify verifies the request authenticity.
+// The request body is read up to the maximum body size
+// and replaced so it can be read again. The requests whose
+// body exceeds the maximum size fail with ErrBodyTooLarge.
+// The token query parameter of the verified TokenScheme
+// requests is removed from the request URL.
+func (v *Verifier) Verify(r *http.Request) error {
+	if len(v.secrets) == 0 {
+		return ErrNoSecret
+	}
+
+	if v.opts.Scheme == TokenScheme {
+		// NOTE: Vocode sends no timestamp so it's only checked if present.
+		if r.Header.Get(v.opts.TimestampHeader) != "" {
+			if _, err := v.timestamp(r); err != nil {
+				return err
+			}
+		}
+		token := r.Header.Get(v.opts.TokenHeader)
+		if token == "" {
+			token = r.URL.Query().Get(v.opts.TokenParam)
+		}
+		if token == "" {
+			return ErrMissingSignature
+		}
+		for _, secret := range v.secrets {
+			if subtle.ConstantTimeCompare([]byte(token), secret) == 1 {
+				// NOTE: the token is removed from the URL query so
+				// it does not end up in the GET delivery payload.
+				q := r.URL.Query()
+				if q.Has(v.opts.TokenParam) {
+					q.Del(v.opts.TokenParam)
+					r.URL.RawQuery = q.Encode()
+				}
+				return nil
+			}
+		}
+		return ErrInvalidSignature
+	}
+
+	ts, err := v.timestamp(r)
+	if err != nil {
+		return err
+	}
+	sigVal := r.Header.Get(v.opts.SignatureHeader)
+	if sigVal == "" {
+		return ErrMissingSignature
+	}
+	sig, err := hex.DecodeString(sigVal)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	payload, err := signedPayload(r, v.opts.MaxBodySize)
+	if err != nil {
+		return err
+	}
+	for _, secret := range v.secrets {
+		if hmac.Equal(sig, mac(secret, ts, payload)) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// timestamp returns the request timestamp. It fails with
+// ErrStaleTimestamp if it is older than the tolerance.
+func (v *Verifier) timestamp(r *http.Request) (int64, error) {
+	tsVal := r.Header.Get(v.opts.TimestampHeader)
+	if tsVal == "" {
+		return 0, ErrMissingTimestamp
+	}
+	ts, err := strconv.ParseInt(tsVal, 10, 64)
+	if err != nil {
+		return 0, ErrMissingTimestamp
+	}
+	if age := v.opts.Now().Sub(time.Unix(ts, 0)); age > v.opts.Tolerance || age < -v.opts.Tolerance {
+		return 0, ErrStaleTimestamp
+	}
+	return ts, nil
+}
+
+// Middleware returns http.Handler which responds with
+// 401 Unauthorized to the requests that fail verification
+// and passes the verified ones to next. The requests whose
+// body is too large are rejected with 400 Bad Request.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.Verify(r); err != nil {
+			http.Error(w, err.Error(), verifyStatus(err))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// verifyStatus returns the HTTP status code for the verification error.
+// The requests whose body is too large are malformed deliveries.
+func verifyStatus(err error) int {
+	if errors.Is(err, ErrBodyTooLarge) {
+		return http.StatusBadRequest
+	}
+	return http.StatusUnauthorized
+}
+
+// URL returns rawURL with the primary secret set in the token query parameter.
+// Vocode does not sign the webhook requests on its own, so the TokenScheme
+// verifiers must be subscribed with the URL returned by this method.
+// Anyone who learns the URL can replay the deliveries; see TokenScheme.
+func (v *Verifier) URL(rawURL string) (string, error) {
+	if len(v.secrets) == 0 {
+		return "", ErrNoSecret
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set(v.opts.TokenParam, string(v.secrets[0]))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// CreateWebhook creates a new webhook subscription whose URL carries the verifier secret.
+func (v *Verifier) CreateWebhook(ctx context.Context, c *vocode.Client, createReq *vocode.CreateWebhookReq) (*vocode.Webhook, error) {
+	u, err := v.URL(createReq.URL)
+	if err != nil {
+		return nil, err
+	}
+	req := *createReq
+	req.URL = u
+	return c.CreateWebhook(ctx, &req)
+}
+
+// UpdateWebhook updates the webhook subscription so its URL carries the verifier secret.
+// It is used to rotate the secrets: first add the new secret as the primary one
+// while keeping the old secret in the verifier and then update the webhook.
+func (v *Verifier) UpdateWebhook(ctx context.Context, c *vocode.Client, id string, updateReq *vocode.UpdateWebhookReq) (*vocode.Webhook, error) {
+	u, err := v.URL(updateReq.URL)
+	if err != nil {
+		return nil, err
+	}
+	req := *updateReq
+	req.URL = u
+	return c.UpdateWebhook(ctx, id, &req)
+}
+
+// Signer signs the webhook requests.
+// It produces the requests the HMACScheme Verifier accepts
+// and is mostly useful for generating test fixtures.
+type Signer struct {
+	secret []byte
+	opts   VerifierOptions
+}
+
+// NewSigner creates a new Signer and returns it.
+func NewSigner(secret string, opts ...VerifierOption) *Signer {
+	return &Signer{
+		secret: []byte(secret),
+		opts:   newVerifierOptions(opts...),
+	}
+}
+
+// Sign signs the request r at time ts.
+// The request body is read and replaced so it can be read again.
+func (s *Signer) Sign(r *http.Request, ts time.Time) error {
+	payload, err := signedPayload(r, s.opts.MaxBodySize)
+	if err != nil {
+		return err
+	}
+	r.Header.Set(s.opts.TimestampHeader, strconv.FormatInt(ts.Unix(), 10))
+	if s.opts.Scheme == TokenScheme {
+		r.Header.Set(s.opts.TokenHeader, string(s.secret))
+		return nil
+	}
+	r.Header.Set(s.opts.SignatureHeader, Signature(s.secret, ts, payload))
+	return nil
+}
+
+// Signature returns hex encoded HMAC-SHA256 signature of the payload at time ts.
+func Signature(secret []byte, ts time.Time, payload []byte) string {
+	return hex.EncodeToString(mac(secret, ts.Unix(), payload))
+}
+
+// NewSecret generates a new random webhook secret.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func mac(secret []byte, ts int64, payload []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(strconv.FormatInt(ts, 10)))
+	h.Write([]byte("."))
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// signedPayload returns the signed request payload:
+// the request body, read up to maxBodySize bytes, for POST
+// requests and the raw URL query for all the other requests.
+func signedPayload(r *http.Request, maxBodySize int64) ([]byte, error) {
+	if r.Method != http.MethodPost {
+		return []byte(r.URL.RawQuery), nil
+	}
+	if r.Body == nil {
+		return nil, nil
+	}
+	data, err := readBody(r, maxBodySize)
+	if err != nil {
+		return nil, err
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return data, nil
+}
@@ -0,0 +1,186 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifier(t *testing.T) {
+	t.Parallel()
+	const secret = "s3cr3t"
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time { return now }
+
+	newReq := func(t *testing.T, signer *Signer, ts time.Time) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(callEndedPayload))
+		if signer != nil {
+			if err := signer.Sign(req, ts); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return req
+	}
+
+	t.Run("valid_signature", func(t *testing.T) {
+		t.Parallel()
+		v := NewVerifier([]string{secret}, WithNow(clock))
+		req := newReq(t, NewSigner(secret), now)
+		if err := v.Verify(req); err != nil {
+			t.Fatal(err)
+		}
+		// NOTE: the body must be readable after verification
+		data, err := ReadRequest(req, DefaultMaxBodySize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != callEndedPayload {
+			t.Fatalf("expected body: %s, got: %s", callEndedPayload, data)
+		}
+	})
+	t.Run("rotated_secret", func(t *testing.T) {
+		t.Parallel()
+		v := NewVerifier([]string{"new", secret}, WithNow(clock))
+		if err := v.Verify(newReq(t, NewSigner(secret), now)); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("invalid_signature", func(t *testing.T) {
+		t.Parallel()
+		v := NewVerifier([]string{secret}, WithNow(clock))
+		if err := v.Verify(newReq(t, NewSigner("other"), now)); !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("expected error: %v, got: %v", ErrInvalidSignature, err)
+		}
+	})
+	t.Run("stale_timestamp", func(t *testing.T) {
+		t.Parallel()
+		v := NewVerifier([]string{secret}, WithNow(clock))
+		req := newReq(t, NewSigner(secret), now.Add(-2*DefaultTolerance))
+		if err := v.Verify(req); !errors.Is(err, ErrStaleTimestamp) {
+			t.Fatalf("expected error: %v, got: %v", ErrStaleTimestamp, err)
+		}
+	})
+	t.Run("missing_signature", func(t *testing.T) {
+		t.Parallel()
+		v := NewVerifier([]string{secret}, WithNow(clock))
+		if err := v.Verify(newReq(t, nil, now)); !errors.Is(err, ErrMissingTimestamp) {
+			t.Fatalf("expected error: %v, got: %v", ErrMissingTimestamp, err)
+		}
+	})
+	t.Run("custom_headers", func(t *testing.T) {
+		t.Parallel()
+		opts := []VerifierOption{
+			WithNow(clock),
+			WithSignatureHeader("X-Sig"),
+			WithTimestampHeader("X-Ts"),
+		}
+		v := NewVerifier([]string{secret}, opts...)
+		req := newReq(t, NewSigner(secret, opts...), now)
+		if req.Header.Get("X-Sig") == "" {
+			t.Fatal("expected custom signature header")
+		}
+		if err := v.Verify(req); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("token_url", func(t *testing.T) {
+		t.Parallel()
+		v := NewVerifier([]string{secret}, WithScheme(TokenScheme), WithNow(clock))
+		u, err := v.URL("https://example.com/hook?foo=bar")
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := url.Parse(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Query().Get(DefaultTokenParam) != secret || parsed.Query().Get("foo") != "bar" {
+			t.Fatalf("unexpected webhook URL: %s", u)
+		}
+		newTokenReq := func(uri string, ts time.Time) *http.Request {
+			req := httptest.NewRequest(http.MethodPost, uri, strings.NewReader(callEndedPayload))
+			req.Header.Set(DefaultTimestampHeader, strconv.FormatInt(ts.Unix(), 10))
+			return req
+		}
+		if err := v.Verify(newTokenReq(parsed.RequestURI(), now)); err != nil {
+			t.Fatal(err)
+		}
+		if err := v.Verify(newTokenReq("/hook?token=wrong", now)); !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("expected error: %v, got: %v", ErrInvalidSignature, err)
+		}
+
+		// the requests with the stale timestamp are rejected
+		if err := v.Verify(newTokenReq(parsed.RequestURI(), now.Add(-2*DefaultTolerance))); !errors.Is(err, ErrStaleTimestamp) {
+			t.Fatalf("expected error: %v, got: %v", ErrStaleTimestamp, err)
+		}
+		// the Vocode deliveries carry no timestamp
+		req := httptest.NewRequest(http.MethodPost, parsed.RequestURI(), strings.NewReader(callEndedPayload))
+		if err := v.Verify(req); err != nil {
+			t.Fatal(err)
+		}
+
+		// the token signer sets the timestamp
+		req = newReq(t, NewSigner(secret, WithScheme(TokenScheme)), now)
+		if err := v.Verify(req); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("body_too_large", func(t *testing.T) {
+		t.Parallel()
+		v := NewVerifier([]string{secret}, WithNow(clock), WithVerifierMaxBodySize(8))
+		if err := v.Verify(newReq(t, NewSigner(secret), now)); !errors.Is(err, ErrBodyTooLarge) {
+			t.Fatalf("expected error: %v, got: %v", ErrBodyTooLarge, err)
+		}
+
+		// the handler limits the body before it's verified
+		v = NewVerifier([]string{secret}, WithNow(clock), WithVerifierMaxBodySize(0))
+		h := NewHandler(func(context.Context, Event) error { return nil }, WithVerifier(v), WithMaxBodySize(8))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newReq(t, NewSigner(secret), now))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status: %d, got: %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+	t.Run("token_query", func(t *testing.T) {
+		t.Parallel()
+		v := NewVerifier([]string{secret}, WithScheme(TokenScheme), WithNow(clock))
+		u, err := v.URL("/hook?type=event_message&call=%7B%22id%22%3A%22call-1%22%7D")
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodGet, u, nil)
+		if err := v.Verify(req); err != nil {
+			t.Fatal(err)
+		}
+		data, err := ReadRequest(req, DefaultMaxBodySize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("expected payload without the token, got: %s", data)
+		}
+	})
+	t.Run("handler", func(t *testing.T) {
+		t.Parallel()
+		v := NewVerifier([]string{secret}, WithNow(clock))
+		h := NewHandler(func(context.Context, Event) error { return nil }, WithVerifier(v))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newReq(t, nil, now))
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status: %d, got: %d", http.StatusUnauthorized, rec.Code)
+		}
+
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, newReq(t, NewSigner(secret), now))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status: %d, got: %d", http.StatusOK, rec.Code)
+		}
+	})
+}
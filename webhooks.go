@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/url"
 
@@ -83,11 +84,9 @@ func (c *Client) ListWebhooks(ctx context.Context, paging *PageParams) (*Webhook
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
+	var options []request.HTTPOption
 	if paging != nil {
-		request.WithPageParams(paging.Encode())
+		options = append(options, request.WithPageParams(paging.Encode()))
 	}
 
 	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
@@ -95,7 +94,7 @@ func (c *Client) ListWebhooks(ctx context.Context, paging *PageParams) (*Webhook
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -108,17 +107,24 @@ func (c *Client) ListWebhooks(ctx context.Context, paging *PageParams) (*Webhook
 	return webhooks, nil
 }
 
+// AllWebhooks returns Pager which iterates over all the webhooks.
+func (c *Client) AllWebhooks(ctx context.Context, opts *ListOptions) *Pager[Webhook] {
+	return newPager(ctx, opts, func(ctx context.Context, paging *PageParams) ([]Webhook, *Paging, error) {
+		page, err := c.ListWebhooks(ctx, paging)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Items, page.Paging, nil
+	})
+}
+
 func (c *Client) GetWebhook(ctx context.Context, id string) (*Webhook, error) {
 	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/webhooks")
 	if err != nil {
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil, options...)
+	req, err := request.NewHTTP(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -126,7 +132,7 @@ func (c *Client) GetWebhook(ctx context.Context, id string) (*Webhook, error) {
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -152,16 +158,12 @@ func (c *Client) CreateWebhook(ctx context.Context, createReq *CreateWebhookReq)
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -187,11 +189,7 @@ func (c *Client) UpdateWebhook(ctx context.Context, id string, updateReq *Update
 		return nil, err
 	}
 
-	options := []request.HTTPOption{
-		request.WithBearer(c.opts.APIKey),
-	}
-
-	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body, options...)
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +197,7 @@ func (c *Client) UpdateWebhook(ctx context.Context, id string, updateReq *Update
 	q.Add("id", id)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := request.Do[*APIError](c.opts.HTTPClient, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -211,3 +209,29 @@ func (c *Client) UpdateWebhook(ctx context.Context, id string, updateReq *Update
 	}
 	return webhook, nil
 }
+
+// DeleteWebhook deletes the webhook with the given id.
+// It returns error matching ErrNotFound if the webhook does not exist.
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	u, err := url.Parse(c.opts.BaseURL + "/" + c.opts.Version + "/webhooks/delete")
+	if err != nil {
+		return err
+	}
+
+	req, err := request.NewHTTP(ctx, http.MethodPost, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	q.Add("id", id)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}